@@ -3,8 +3,12 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 	"web-crawler/backend/internal/config"
 	"web-crawler/backend/internal/routes"
+	"web-crawler/backend/internal/services"
+	"web-crawler/backend/internal/services/crawler"
+	"web-crawler/backend/internal/urlnorm"
 	"web-crawler/backend/internal/websocket"
 	"web-crawler/backend/models"
 
@@ -20,12 +24,42 @@ func main() {
 		log.Fatalf("failed to connect database: %v", err)
 	}
 
-	db.AutoMigrate(&models.Website{})
+	db.AutoMigrate(&models.Website{}, &models.Page{}, &models.Link{}, &models.SitemapIssue{}, &models.CrawlRun{}, &models.CrawlJob{}, &models.Schedule{}, &models.CrawlCheckpoint{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.AlertRule{}, &models.Alert{}, &models.Project{}, &models.Tag{}, &models.User{}, &models.Session{})
 
-	hub := websocket.NewHub()
+	hub := websocket.NewHub(cfg.WSReplayBuffer)
 	go hub.Run()
 
-	router := routes.SetupRoutes(db, cfg, hub)
+	normalizer := urlnorm.New(cfg.URLTrackingParams)
+	crawlerService := crawler.NewService(db, cfg, normalizer)
+	jobQueue := services.NewJobQueue(db, cfg.JobLeaseDuration)
+	webhookService := services.NewWebhookService(db, cfg.WebhookTimeout, cfg.WebhookMaxAttempts, cfg.WebhookRetryBaseDelay)
+	go webhookService.RunDeliveries(cfg.WebhookPollInterval)
+
+	alertService := services.NewAlertService(db, hub)
+
+	urlService := services.NewURLService(db, hub, crawlerService, jobQueue, webhookService, alertService, normalizer)
+	if err := urlService.BackfillNormalizedURLs(); err != nil {
+		log.Printf("failed to backfill normalized URLs: %v", err)
+	}
+	if err := urlService.RecoverScans(); err != nil {
+		log.Printf("failed to recover scans: %v", err)
+	}
+	go urlService.RunScanWorkers(cfg.CrawlWorkers, cfg.JobPollInterval)
+	if cfg.TrashRetentionDays > 0 {
+		go urlService.RunTrashPurge(time.Duration(cfg.TrashRetentionDays)*24*time.Hour, cfg.TrashPurgeInterval)
+	}
+
+	scheduleService := services.NewScheduleService(db, urlService)
+	go scheduleService.RunScheduler(cfg.SchedulerPollInterval)
+
+	userService := services.NewUserService(db, cfg.SessionTTL)
+	if cfg.AdminEmail != "" {
+		if err := userService.BootstrapAdmin(cfg.AdminEmail, cfg.AdminPassword); err != nil {
+			log.Fatalf("failed to create admin user: %v", err)
+		}
+	}
+
+	router := routes.SetupRoutes(db, cfg, hub, urlService, scheduleService, webhookService, alertService, userService)
 
 	fmt.Println("Starting server on port 8080...")
 	if err := router.Run(":8080"); err != nil {
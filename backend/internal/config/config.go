@@ -2,13 +2,73 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 )
 
 type Config struct {
 	DBSource string `env:"DB_SOURCE,required"`
-	APIKey   string `env:"API_KEY,required"`
+
+	// SessionTTL is how long a user stays signed in after logging in
+	SessionTTL time.Duration `env:"SESSION_TTL" envDefault:"24h"`
+	// AdminEmail and AdminPassword create the first admin when there are no users yet
+	AdminEmail    string `env:"ADMIN_EMAIL"`
+	AdminPassword string `env:"ADMIN_PASSWORD"`
+
+	// UserAgent is sent with every crawler request and matched against robots.txt groups
+	UserAgent string `env:"CRAWLER_USER_AGENT" envDefault:"web-crawler"`
+
+	// URLTrackingParams are dropped from URLs when they are normalized; a trailing "*" matches
+	// any parameter starting with the rest of the name
+	URLTrackingParams []string `env:"URL_TRACKING_PARAMS" envSeparator:"," envDefault:"utm_*,gclid,dclid,fbclid,msclkid,yclid,mc_cid,mc_eid,_ga,_gl,igshid"`
+
+	// CrawlWorkers is the number of scans run at once, CrawlParallelism the number of
+	// concurrent page requests within a single scan
+	CrawlWorkers     int `env:"CRAWL_WORKERS" envDefault:"4"`
+	CrawlParallelism int `env:"CRAWL_PARALLELISM" envDefault:"8"`
+
+	// CrawlProgressInterval is the minimum time between two progress events of a crawl
+	CrawlProgressInterval time.Duration `env:"CRAWL_PROGRESS_INTERVAL" envDefault:"1s"`
+
+	// Failed page requests are retried with exponential backoff, starting at CrawlRetryBaseDelay
+	CrawlMaxRetries     int           `env:"CRAWL_MAX_RETRIES" envDefault:"3"`
+	CrawlRetryBaseDelay time.Duration `env:"CRAWL_RETRY_BASE_DELAY" envDefault:"1s"`
+	CrawlRetryMaxDelay  time.Duration `env:"CRAWL_RETRY_MAX_DELAY" envDefault:"30s"`
+
+	// Link verification limits, applied per crawl
+	LinkCheckConcurrency int           `env:"LINK_CHECK_CONCURRENCY" envDefault:"16"`
+	LinkCheckPerHost     int           `env:"LINK_CHECK_PER_HOST" envDefault:"4"`
+	LinkCheckTimeout     time.Duration `env:"LINK_CHECK_TIMEOUT" envDefault:"10s"`
+
+	// Scan job queue; a running job whose lease is not renewed in time is handed to another worker
+	JobLeaseDuration time.Duration `env:"JOB_LEASE_DURATION" envDefault:"1m"`
+	JobPollInterval  time.Duration `env:"JOB_POLL_INTERVAL" envDefault:"2s"`
+
+	// WebSocket handshakes are only accepted from these origins ("*" allows any), and
+	// tickets issued for them expire after WSTicketTTL. Tickets are signed with WSTicketSecret,
+	// which every server instance must share
+	WSAllowedOrigins []string      `env:"WS_ALLOWED_ORIGINS" envSeparator:"," envDefault:"http://localhost:3000"`
+	WSTicketSecret   string        `env:"WS_TICKET_SECRET,required"`
+	WSTicketTTL      time.Duration `env:"WS_TICKET_TTL" envDefault:"30s"`
+
+	// WSReplayBuffer is the number of recent events kept for clients resuming after a reconnect
+	WSReplayBuffer int `env:"WS_REPLAY_BUFFER" envDefault:"1000"`
+
+	// Webhook deliveries time out after WebhookTimeout and are attempted up to WebhookMaxAttempts
+	// times, waiting exponentially longer from WebhookRetryBaseDelay between attempts
+	WebhookTimeout        time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	WebhookMaxAttempts    int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"6"`
+	WebhookRetryBaseDelay time.Duration `env:"WEBHOOK_RETRY_BASE_DELAY" envDefault:"30s"`
+	WebhookPollInterval   time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"5s"`
+
+	// Deleted websites stay in the trash for TrashRetentionDays days, 0 keeps them until purged by hand;
+	// the trash is checked for expired websites every TrashPurgeInterval
+	TrashRetentionDays int           `env:"TRASH_RETENTION_DAYS" envDefault:"30"`
+	TrashPurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" envDefault:"1h"`
+
+	// SchedulerPollInterval is how often scheduled scans are checked for being due
+	SchedulerPollInterval time.Duration `env:"SCHEDULER_POLL_INTERVAL" envDefault:"30s"`
 }
 
 func Load() Config {
@@ -0,0 +1,83 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string
+	}{
+		{"every minute", "* * * * *", "2025-06-02T10:50:30Z", "2025-06-02T10:51:00Z"},
+		{"strictly after", "50 10 * * *", "2025-06-02T10:50:00Z", "2025-06-03T10:50:00Z"},
+		{"step within range", "*/15 9-10 * * *", "2025-06-02T10:20:00Z", "2025-06-02T10:30:00Z"},
+		{"range ends rolls over to next day", "*/15 9-10 * * *", "2025-06-02T10:50:00Z", "2025-06-03T09:00:00Z"},
+		{"step from a start value", "5/20 * * * *", "2025-06-02T00:45:00Z", "2025-06-02T01:05:00Z"},
+		{"list", "0 6,18 * * *", "2025-06-02T07:00:00Z", "2025-06-02T18:00:00Z"},
+		{"day of month or day of week, weekday first", "0 0 13 * 5", "2025-06-01T00:00:00Z", "2025-06-06T00:00:00Z"},
+		{"day of month or day of week, day first", "0 0 10 * 5", "2025-06-07T00:00:00Z", "2025-06-10T00:00:00Z"},
+		{"day of week only", "0 0 * * 1", "2025-06-03T00:00:00Z", "2025-06-09T00:00:00Z"},
+		{"day of month only", "0 0 15 * *", "2025-06-16T00:00:00Z", "2025-07-15T00:00:00Z"},
+		{"seven is Sunday", "0 0 * * 7", "2025-06-02T00:00:00Z", "2025-06-08T00:00:00Z"},
+		{"zero is Sunday", "0 0 * * 0", "2025-06-02T00:00:00Z", "2025-06-08T00:00:00Z"},
+		{"day names", "0 0 * * sat,sun", "2025-06-02T00:00:00Z", "2025-06-07T00:00:00Z"},
+		{"month names", "0 0 1 jan *", "2025-06-02T00:00:00Z", "2026-01-01T00:00:00Z"},
+		{"skips months without the day", "0 0 31 * *", "2025-04-01T00:00:00Z", "2025-05-31T00:00:00Z"},
+		{"month rollover into next year", "0 0 1 * *", "2025-12-15T00:00:00Z", "2026-01-01T00:00:00Z"},
+		{"leap day", "0 0 29 2 *", "2025-03-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"end of month into next month", "30 23 * * *", "2025-01-31T23:45:00Z", "2025-02-01T23:30:00Z"},
+		{"macro", "@weekly", "2025-01-01T12:00:00Z", "2025-01-05T00:00:00Z"},
+		{"times are UTC", "0 12 * * *", "2025-06-02T13:30:00+02:00", "2025-06-02T12:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.expr, err)
+			}
+			got := expr.Next(mustTime(t, tt.from))
+			if want := mustTime(t, tt.want); !got.Equal(want) {
+				t.Errorf("Next(%s) of %q = %s, want %s", tt.from, tt.expr, got.Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"too few fields", "* * * *"},
+		{"too many fields", "* * * * * *"},
+		{"minute out of range", "60 * * * *"},
+		{"day out of range", "0 0 0 * *"},
+		{"weekday out of range", "0 0 * * 8"},
+		{"unknown name", "0 0 * foo *"},
+		{"zero step", "*/0 * * * *"},
+		{"reversed range", "5-1 * * * *"},
+		{"never matches", "0 0 30 2 *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.expr); err == nil {
+				t.Errorf("Parse(%q) succeeded, want an error", tt.expr)
+			}
+		})
+	}
+}
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -0,0 +1,151 @@
+// Package export writes tables of rows as CSV, JSON Lines or XLSX, one row at a time.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Formats lists the supported formats.
+var Formats = []string{"csv", "jsonl", "xlsx"}
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Writer writes the rows of a table. Cells are strings, integers, booleans, times, or nil for empty cells.
+type Writer interface {
+	WriteRow(cells []any) error
+	// Close writes what remains buffered. It does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter returns a writer of the given format. CSV and XLSX start with a header row of columns,
+// JSON Lines writes each row as an object keyed by columns.
+func NewWriter(format string, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case "csv":
+		return newCSVWriter(w, columns)
+	case "jsonl":
+		return &jsonlWriter{w: bufio.NewWriter(w), columns: columns}, nil
+	case "xlsx":
+		return newXLSXWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// ContentType returns the MIME type of a format.
+func ContentType(format string) string {
+	switch format {
+	case "csv":
+		return "text/csv; charset=utf-8"
+	case "jsonl":
+		return "application/x-ndjson"
+	case "xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+// formatCell returns the text of a cell, as written to CSV files and XLSX string cells.
+func formatCell(cell any) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	if err := cw.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) WriteRow(cells []any) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = formatCell(cell)
+		if _, ok := cell.(string); ok {
+			record[i] = escapeFormula(record[i])
+		}
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// escapeFormula keeps spreadsheet applications from evaluating text that starts like a
+// formula, since titles and URLs come from crawled websites.
+func escapeFormula(text string) string {
+	if text == "" {
+		return text
+	}
+	switch text[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + text
+	}
+	return text
+}
+
+type jsonlWriter struct {
+	w       *bufio.Writer
+	columns []string
+}
+
+func (jw *jsonlWriter) WriteRow(cells []any) error {
+	// Build the object by hand to keep the column order
+	jw.w.WriteByte('{')
+	for i, cell := range cells {
+		if i > 0 {
+			jw.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(jw.columns[i])
+		value, err := json.Marshal(cell)
+		if err != nil {
+			return err
+		}
+		jw.w.Write(key)
+		jw.w.WriteByte(':')
+		jw.w.Write(value)
+	}
+	jw.w.WriteString("}\n")
+	// Flush after each row so that rows reach the client as they are read
+	return jw.w.Flush()
+}
+
+func (jw *jsonlWriter) Close() error {
+	return jw.w.Flush()
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"web-crawler/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AlertHandler struct {
+	AlertService *services.AlertService
+}
+
+func NewAlertHandler(service *services.AlertService) *AlertHandler {
+	return &AlertHandler{AlertService: service}
+}
+
+type alertRuleRequest struct {
+	Name      string `json:"name"`
+	WebsiteID *uint  `json:"websiteId"`
+	Metric    string `json:"metric" binding:"required"`
+	Operator  string `json:"operator" binding:"required"`
+	Value     string `json:"value"`
+	Enabled   *bool  `json:"enabled"`
+}
+
+func (r alertRuleRequest) toInput() services.AlertRuleInput {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return services.AlertRuleInput{
+		Name:      r.Name,
+		WebsiteID: r.WebsiteID,
+		Metric:    r.Metric,
+		Operator:  r.Operator,
+		Value:     r.Value,
+		Enabled:   enabled,
+	}
+}
+
+func (h *AlertHandler) GetAlerts(c *gin.Context) {
+	page, limit := getPagination(c)
+	params := services.GetAlertsParams{
+		Page:      page,
+		Limit:     limit,
+		State:     c.Query("state"),
+		WebsiteID: c.Query("websiteId"),
+		RuleID:    c.Query("ruleId"),
+	}
+
+	alerts, totalItems, err := h.AlertService.GetAlerts(params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve alerts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       alerts,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
+
+func (h *AlertHandler) GetAlertRules(c *gin.Context) {
+	page, limit := getPagination(c)
+	rules, totalItems, err := h.AlertService.GetAlertRules(page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve alert rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       rules,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
+
+func (h *AlertHandler) GetAlertRule(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.AlertService.GetAlertRule(id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve alert rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *AlertHandler) CreateAlertRule(c *gin.Context) {
+	var req alertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	rule, err := h.AlertService.CreateAlertRule(req.toInput())
+	if err != nil {
+		h.writeError(c, err, "Failed to create alert rule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *AlertHandler) UpdateAlertRule(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req alertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	rule, err := h.AlertService.UpdateAlertRule(id, req.toInput())
+	if err != nil {
+		h.writeError(c, err, "Failed to update alert rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *AlertHandler) DeleteAlertRule(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.AlertService.DeleteAlertRule(id); err != nil {
+		h.writeError(c, err, "Failed to delete alert rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert rule deleted successfully"})
+}
+
+func (h *AlertHandler) writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   err.Error(),
+			"message": "Alert rule or URL not found",
+		})
+	case errors.Is(err, services.ErrInvalidAlertRule):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid alert rule",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": message,
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"web-crawler/backend/internal/middleware"
+	"web-crawler/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	UserService *services.UserService
+}
+
+func NewAuthHandler(service *services.UserService) *AuthHandler {
+	return &AuthHandler{UserService: service}
+}
+
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req struct {
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	token, session, user, err := h.UserService.Login(req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   err.Error(),
+				"message": "Invalid email or password",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to log in",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"token": token, "expiresAt": session.ExpiresAt, "user": user})
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if token := middleware.BearerToken(c); token != "" {
+		if err := h.UserService.Logout(token); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   err.Error(),
+				"message": "Failed to log out",
+			})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
+	c.JSON(http.StatusOK, middleware.CurrentUser(c))
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req struct {
+		CurrentPassword string `json:"currentPassword" binding:"required"`
+		NewPassword     string `json:"newPassword" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	user := middleware.CurrentUser(c)
+	err := h.UserService.ChangePassword(user.ID, middleware.BearerToken(c), req.CurrentPassword, req.NewPassword)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+	case errors.Is(err, services.ErrInvalidCredentials):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   err.Error(),
+			"message": "Current password is incorrect",
+		})
+	case errors.Is(err, services.ErrInvalidUser):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid new password",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to change password",
+		})
+	}
+}
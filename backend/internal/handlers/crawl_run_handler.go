@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"web-crawler/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CrawlRunHandler struct {
+	CrawlRunService *services.CrawlRunService
+}
+
+func NewCrawlRunHandler(service *services.CrawlRunService) *CrawlRunHandler {
+	return &CrawlRunHandler{CrawlRunService: service}
+}
+
+func (h *CrawlRunHandler) GetRuns(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	page, limit := getPagination(c)
+	runs, totalItems, err := h.CrawlRunService.GetRuns(id, page, limit)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "URL not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve crawl runs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       runs,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
+
+func (h *CrawlRunHandler) DiffRuns(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	diff, err := h.CrawlRunService.DiffRuns(id, c.Query("from"), c.Query("to"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, services.ErrRunNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "URL or crawl runs not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to compare crawl runs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"web-crawler/backend/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseKeepAlive is how often an idle event stream sends a comment, so that proxies keep it open.
+const sseKeepAlive = 15 * time.Second
+
+type EventHandler struct {
+	Hub *websocket.Hub
+}
+
+func NewEventHandler(hub *websocket.Hub) *EventHandler {
+	return &EventHandler{Hub: hub}
+}
+
+// StreamEvents streams the events of the websocket hub as server-sent events, each with its
+// sequence number as id, so that clients reconnecting with Last-Event-ID get the events they missed.
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	var websiteIDs []uint
+	for _, value := range c.QueryArray("websiteId") {
+		for _, part := range strings.Split(value, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil || id == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "invalid websiteId: " + part,
+					"message": "Invalid website ID",
+				})
+				return
+			}
+			websiteIDs = append(websiteIDs, uint(id))
+		}
+	}
+
+	// EventSource sends Last-Event-ID on reconnect, other clients may pass it in the query
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	var resumeFrom *uint64
+	if lastEventID != "" {
+		seq, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid Last-Event-ID: " + lastEventID,
+				"message": "Invalid event ID",
+			})
+			return
+		}
+		resumeFrom = &seq
+	}
+
+	subscription := h.Hub.Subscribe(websiteIDs, resumeFrom)
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case message, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			if err := writeSSE(c.Writer, message); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeSSE writes the events of a hub message, one per line, as server-sent events named after their type.
+func writeSSE(w gin.ResponseWriter, message []byte) error {
+	for _, data := range bytes.Split(message, []byte("\n")) {
+		if len(data) == 0 {
+			continue
+		}
+		var event struct {
+			Type websocket.EventType `json:"type"`
+			Seq  uint64              `json:"seq"`
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return err
+		}
+
+		var frame bytes.Buffer
+		if event.Seq != 0 {
+			fmt.Fprintf(&frame, "id: %d\n", event.Seq)
+		}
+		fmt.Fprintf(&frame, "event: %s\ndata: %s\n\n", event.Type, data)
+		if _, err := w.Write(frame.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"web-crawler/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type LinkHandler struct {
+	LinkService *services.LinkService
+}
+
+func NewLinkHandler(service *services.LinkService) *LinkHandler {
+	return &LinkHandler{LinkService: service}
+}
+
+func (h *LinkHandler) GetLinks(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	page, limit := getPagination(c)
+	params := services.GetLinksParams{
+		Page:       page,
+		Limit:      limit,
+		RunID:      c.Query("runId"),
+		Search:     c.Query("search"),
+		Type:       c.Query("type"),
+		Broken:     c.Query("broken"),
+		StatusCode: c.Query("statusCode"),
+		SourceURL:  c.Query("sourceUrl"),
+	}
+
+	links, totalItems, err := h.LinkService.GetLinks(id, params)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "URL not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve links",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       links,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"web-crawler/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PageHandler struct {
+	PageService *services.PageService
+}
+
+func NewPageHandler(service *services.PageService) *PageHandler {
+	return &PageHandler{PageService: service}
+}
+
+func (h *PageHandler) GetPages(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	page, limit := getPagination(c)
+	params := services.GetPagesParams{
+		Page:    page,
+		Limit:   limit,
+		RunID:   c.Query("runId"),
+		Skipped: c.Query("skipped"),
+	}
+
+	pages, totalItems, err := h.PageService.GetPages(id, params)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "URL not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve pages",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       pages,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
+
+func (h *PageHandler) GetSitemapIssues(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	page, limit := getPagination(c)
+	params := services.GetSitemapIssuesParams{
+		Page:  page,
+		Limit: limit,
+		RunID: c.Query("runId"),
+		Kind:  c.Query("kind"),
+	}
+
+	issues, totalItems, err := h.PageService.GetSitemapIssues(id, params)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "URL not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve sitemap issues",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       issues,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
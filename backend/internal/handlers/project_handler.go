@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"web-crawler/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ProjectHandler struct {
+	ProjectService *services.ProjectService
+}
+
+func NewProjectHandler(service *services.ProjectService) *ProjectHandler {
+	return &ProjectHandler{ProjectService: service}
+}
+
+type projectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+func (r projectRequest) toInput() services.ProjectInput {
+	return services.ProjectInput{Name: r.Name, Description: r.Description}
+}
+
+func (h *ProjectHandler) GetProjects(c *gin.Context) {
+	page, limit := getPagination(c)
+	projects, totalItems, err := h.ProjectService.GetProjects(page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve projects",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       projects,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
+
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.ProjectService.GetProject(id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve project")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	var req projectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	project, err := h.ProjectService.CreateProject(req.toInput())
+	if err != nil {
+		h.writeError(c, err, "Failed to create project")
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req projectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	project, err := h.ProjectService.UpdateProject(id, req.toInput())
+	if err != nil {
+		h.writeError(c, err, "Failed to update project")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ProjectService.DeleteProject(id); err != nil {
+		h.writeError(c, err, "Failed to delete project")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
+}
+
+func (h *ProjectHandler) writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   err.Error(),
+			"message": "Project not found",
+		})
+	case errors.Is(err, services.ErrInvalidProject):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid project",
+		})
+	case errors.Is(err, services.ErrProjectExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   err.Error(),
+			"message": "Project already exists",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": message,
+		})
+	}
+}
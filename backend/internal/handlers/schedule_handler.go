@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"web-crawler/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ScheduleHandler struct {
+	ScheduleService *services.ScheduleService
+}
+
+func NewScheduleHandler(service *services.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{ScheduleService: service}
+}
+
+type scheduleRequest struct {
+	Cron     string `json:"cron"`
+	Interval string `json:"interval"`
+	Enabled  *bool  `json:"enabled"`
+}
+
+func (r scheduleRequest) toInput() services.ScheduleInput {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return services.ScheduleInput{Cron: r.Cron, Interval: r.Interval, Enabled: enabled}
+}
+
+func (h *ScheduleHandler) GetSchedules(c *gin.Context) {
+	page, limit := getPagination(c)
+	schedules, totalItems, err := h.ScheduleService.GetSchedules(page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve schedules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       schedules,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
+
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.ScheduleService.GetSchedule(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "Schedule not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve schedule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req scheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	schedule, err := h.ScheduleService.CreateSchedule(id, req.toInput())
+	if err != nil {
+		h.writeError(c, err, "Failed to create schedule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req scheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	schedule, err := h.ScheduleService.UpdateSchedule(id, req.toInput())
+	if err != nil {
+		h.writeError(c, err, "Failed to update schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ScheduleService.DeleteSchedule(id); err != nil {
+		h.writeError(c, err, "Failed to delete schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted successfully"})
+}
+
+func (h *ScheduleHandler) writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   err.Error(),
+			"message": "URL or schedule not found",
+		})
+	case errors.Is(err, services.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid schedule",
+		})
+	case errors.Is(err, services.ErrScheduleAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   err.Error(),
+			"message": "URL already has a schedule",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": message,
+		})
+	}
+}
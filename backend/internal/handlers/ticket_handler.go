@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"web-crawler/backend/internal/middleware"
+	"web-crawler/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketHandler struct {
+	TicketService *services.TicketService
+}
+
+func NewTicketHandler(service *services.TicketService) *TicketHandler {
+	return &TicketHandler{TicketService: service}
+}
+
+// IssueWSTicket issues a WebSocket ticket for the current user.
+func (h *TicketHandler) IssueWSTicket(c *gin.Context) {
+	ticket, expiresAt, err := h.TicketService.Issue(middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to issue WebSocket ticket",
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"ticket": ticket, "expiresAt": expiresAt})
+}
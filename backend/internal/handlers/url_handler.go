@@ -2,9 +2,17 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
+	"web-crawler/backend/internal/export"
 	"web-crawler/backend/internal/services"
+	"web-crawler/backend/internal/urlnorm"
+	"web-crawler/backend/models"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -21,6 +29,7 @@ func NewURLHandler(service *services.URLService) *URLHandler {
 func (h *URLHandler) CreateURL(c *gin.Context) {
 	var newURL struct {
 		URL string `json:"url" binding:"required,url"`
+		crawlSettingsRequest
 	}
 
 	if err := c.ShouldBindJSON(&newURL); err != nil {
@@ -31,7 +40,7 @@ func (h *URLHandler) CreateURL(c *gin.Context) {
 		return
 	}
 
-	website, err := h.URLService.CreateURL(newURL.URL)
+	website, err := h.URLService.CreateURL(newURL.URL, newURL.toSettings())
 	if err != nil {
 		if errors.Is(err, services.ErrURLAlreadyExists) {
 			c.JSON(http.StatusConflict, gin.H{
@@ -40,6 +49,13 @@ func (h *URLHandler) CreateURL(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, urlnorm.ErrInvalidURL) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   err.Error(),
+				"message": "Invalid URL format or missing required field",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
 			"message": "Failed to create URL",
@@ -50,11 +66,77 @@ func (h *URLHandler) CreateURL(c *gin.Context) {
 	c.JSON(http.StatusCreated, website)
 }
 
+type crawlSettingsRequest struct {
+	MaxDepth     *int  `json:"maxDepth" form:"maxDepth" binding:"omitempty,min=0,max=10"`
+	MaxPages     *int  `json:"maxPages" form:"maxPages" binding:"omitempty,min=1,max=1000"`
+	IgnoreRobots *bool `json:"ignoreRobots" form:"ignoreRobots"`
+}
+
+func (r crawlSettingsRequest) toSettings() services.CrawlSettings {
+	return services.CrawlSettings{
+		MaxDepth:     r.MaxDepth,
+		MaxPages:     r.MaxPages,
+		IgnoreRobots: r.IgnoreRobots,
+	}
+}
+
+func (h *URLHandler) UpdateURL(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var settings crawlSettingsRequest
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	website, err := h.URLService.UpdateURLSettings(id, settings.toSettings())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "URL not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to update URL",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, website)
+}
+
 func (h *URLHandler) GetURLs(c *gin.Context) {
 	page, limit := getPagination(c)
-	params := services.GetURLsParams{
-		Page:             page,
-		Limit:            limit,
+	params := getURLsParams(c)
+	params.Page, params.Limit = page, limit
+
+	websites, totalItems, err := h.URLService.GetURLs(params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve URLs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       websites,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
+
+// getURLsParams reads the filters and sort order of the URL list from the query string.
+func getURLsParams(c *gin.Context) services.GetURLsParams {
+	return services.GetURLsParams{
 		Search:           c.Query("search"),
 		Status:           c.Query("status"),
 		HTMLVersion:      c.Query("htmlVersion"),
@@ -69,19 +151,14 @@ func (h *URLHandler) GetURLs(c *gin.Context) {
 		DateCreatedTo:    c.Query("dateCreatedTo"),
 		DateCrawledFrom:  c.Query("dateCrawledFrom"),
 		DateCrawledTo:    c.Query("dateCrawledTo"),
+		Project:          c.Query("project"),
+		Tags:             c.Query("tags"),
 		SortBy:           c.Query("sortBy"),
 		SortOrder:        c.Query("sortOrder"),
 	}
+}
 
-	websites, totalItems, err := h.URLService.GetURLs(params)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   err.Error(),
-			"message": "Failed to retrieve URLs",
-		})
-		return
-	}
-
+func paginationResponse(totalItems int64, page, limit int) gin.H {
 	totalPages := 0
 	if limit > 0 {
 		totalPages = int(totalItems) / limit
@@ -90,15 +167,12 @@ func (h *URLHandler) GetURLs(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data": websites,
-		"pagination": gin.H{
-			"totalItems":  totalItems,
-			"totalPages":  totalPages,
-			"currentPage": page,
-			"pageSize":    limit,
-		},
-	})
+	return gin.H{
+		"totalItems":  totalItems,
+		"totalPages":  totalPages,
+		"currentPage": page,
+		"pageSize":    limit,
+	}
 }
 
 func getPagination(c *gin.Context) (page, limit int) {
@@ -117,6 +191,19 @@ func getPagination(c *gin.Context) (page, limit int) {
 	return page, limit
 }
 
+// parseIDParam reads the :id route parameter and writes a 400 response when it is not a positive integer.
+func parseIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid id: " + c.Param("id"),
+			"message": "Invalid URL ID",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
 func (h *URLHandler) GetURLByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -176,20 +263,55 @@ func (h *URLHandler) DeleteURLById(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "URL deleted successfully"})
 }
 
-func (h *URLHandler) BulkDeleteURLs(c *gin.Context) {
-	var ids struct {
-		IDs []int `json:"ids" binding:"required"`
-	}
+// bulkTargetRequest selects the URLs of a bulk operation by ID, project or tag.
+type bulkTargetRequest struct {
+	IDs       []int  `json:"ids"`
+	ProjectID uint   `json:"projectId"`
+	Tag       string `json:"tag"`
+}
 
-	if err := c.ShouldBindJSON(&ids); err != nil {
+// bindBulkTarget reads the URLs targeted by a bulk operation and writes an error response when they cannot be resolved.
+func (h *URLHandler) bindBulkTarget(c *gin.Context) ([]int, bool) {
+	var req bulkTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   err.Error(),
 			"message": "Invalid request body",
 		})
+		return nil, false
+	}
+
+	ids, err := h.URLService.ResolveBulkTarget(services.BulkTarget{IDs: req.IDs, ProjectID: req.ProjectID, Tag: req.Tag})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidBulkTarget):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   err.Error(),
+				"message": "Invalid request body",
+			})
+		case errors.Is(err, services.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "Project not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   err.Error(),
+				"message": "Failed to resolve the targeted URLs",
+			})
+		}
+		return nil, false
+	}
+	return ids, true
+}
+
+func (h *URLHandler) BulkDeleteURLs(c *gin.Context) {
+	ids, ok := h.bindBulkTarget(c)
+	if !ok {
 		return
 	}
 
-	rowsAffected, err := h.URLService.BulkDeleteURLs(ids.IDs)
+	rowsAffected, err := h.URLService.BulkDeleteURLs(ids)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
@@ -198,7 +320,7 @@ func (h *URLHandler) BulkDeleteURLs(c *gin.Context) {
 		return
 	}
 
-	if rowsAffected == 0 && len(ids.IDs) > 0 {
+	if rowsAffected == 0 && len(ids) > 0 {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "no_urls_found_for_deletion",
 			"message": "None of the provided URLs were found to delete.",
@@ -209,6 +331,155 @@ func (h *URLHandler) BulkDeleteURLs(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "URLs deleted successfully"})
 }
 
+func (h *URLHandler) SetURLProject(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ProjectID *uint `json:"projectId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	website, err := h.URLService.SetURLProject(id, req.ProjectID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, website)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   err.Error(),
+			"message": "URL not found",
+		})
+	case errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   err.Error(),
+			"message": "Project not found",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to update URL project",
+		})
+	}
+}
+
+func (h *URLHandler) SetURLTags(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Tags []string `json:"tags" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	website, err := h.URLService.SetURLTags(id, req.Tags)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, website)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   err.Error(),
+			"message": "URL not found",
+		})
+	case errors.Is(err, services.ErrInvalidTag):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid tag",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to update URL tags",
+		})
+	}
+}
+
+func (h *URLHandler) GetTrashedURLs(c *gin.Context) {
+	page, limit := getPagination(c)
+
+	websites, totalItems, err := h.URLService.GetTrashedURLs(page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve trashed URLs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       websites,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
+
+func (h *URLHandler) RestoreURL(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	website, err := h.URLService.RestoreURL(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "URL not found in the trash",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to restore URL",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, website)
+}
+
+func (h *URLHandler) PurgeURL(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := h.URLService.PurgeURL(id)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "URL purged successfully"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   err.Error(),
+			"message": "URL not found",
+		})
+	case errors.Is(err, services.ErrURLNotTrashed), errors.Is(err, services.ErrScanInProgress):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to purge URL",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to purge URL",
+		})
+	}
+}
+
 func (h *URLHandler) ScanURL(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -229,6 +500,13 @@ func (h *URLHandler) ScanURL(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, services.ErrScanInProgress) || errors.Is(err, services.ErrScanPaused) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   err.Error(),
+				"message": "URL already has a scan",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
 			"message": "Failed to start scan",
@@ -269,19 +547,66 @@ func (h *URLHandler) CancelScanURL(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Scan cancelled successfully"})
 }
 
-func (h *URLHandler) BulkScanURLs(c *gin.Context) {
-	var ids struct {
-		IDs []int `json:"ids" binding:"required"`
+func (h *URLHandler) PauseScanURL(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
 	}
 
-	if err := c.ShouldBindJSON(&ids); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+	if err := h.URLService.PauseScanURL(id); err != nil {
+		if errors.Is(err, services.ErrNoActiveScan) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   err.Error(),
+				"message": "URL has no queued or running scan",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
-			"message": "Invalid request body",
+			"message": "Failed to pause scan",
 		})
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{"message": "Scan paused successfully"})
+}
+
+func (h *URLHandler) ResumeScanURL(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.URLService.ResumeScanURL(id); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "URL not found",
+			})
+		case errors.Is(err, services.ErrNoPausedScan), errors.Is(err, services.ErrScanInProgress):
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   err.Error(),
+				"message": "URL has no paused scan",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   err.Error(),
+				"message": "Failed to resume scan",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scan resumed successfully"})
+}
+
+func (h *URLHandler) BulkScanURLs(c *gin.Context) {
+	ids, ok := h.bindBulkTarget(c)
+	if !ok {
+		return
+	}
+
 	type failedScan struct {
 		ID    int    `json:"id"`
 		Error string `json:"error"`
@@ -289,7 +614,7 @@ func (h *URLHandler) BulkScanURLs(c *gin.Context) {
 	var failures []failedScan
 	var successes int
 
-	for _, id := range ids.IDs {
+	for _, id := range ids {
 		if err := h.URLService.StartScanURL(id); err != nil {
 			failures = append(failures, failedScan{ID: id, Error: err.Error()})
 		} else {
@@ -316,3 +641,122 @@ func (h *URLHandler) BulkScanURLs(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Bulk scan started successfully for all URLs"})
 }
+
+// urlExportColumns are the columns of an exported URL list, with headings counts flattened.
+var urlExportColumns = []string{
+	"id", "url", "status", "title", "htmlVersion",
+	"h1", "h2", "h3", "h4", "h5", "h6",
+	"internalLinks", "externalLinks", "brokenLinks", "hasLoginForm",
+	"pagesCrawled", "robotsSkipped", "sitemapFound", "sitemapUrls", "orphanPages", "unlistedPages",
+	"maxDepth", "maxPages", "ignoreRobots", "failureCode", "failureReason",
+	"createdAt", "crawlStartedAt", "crawlFinishedAt", "nextRunAt", "lastRunAt",
+}
+
+func urlExportRow(website *models.Website) []any {
+	headings := website.HeadingsCount
+	return []any{
+		website.ID, website.URL, string(website.Status), website.Title, website.HTMLVersion,
+		headings["h1"], headings["h2"], headings["h3"], headings["h4"], headings["h5"], headings["h6"],
+		website.InternalLinks, website.ExternalLinks, website.BrokenLinks, website.HasLoginForm,
+		website.PagesCrawled, website.RobotsSkipped, website.SitemapFound, website.SitemapURLs, website.OrphanPages, website.UnlistedPages,
+		website.MaxDepth, website.MaxPages, website.IgnoreRobots, string(website.FailureCode), website.FailureReason,
+		website.CreatedAt, website.CrawlStartedAt, website.CrawlFinishedAt, website.NextRunAt, website.LastRunAt,
+	}
+}
+
+// ExportURLs streams every URL matching the list filters as CSV, JSON Lines or XLSX.
+func (h *URLHandler) ExportURLs(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if !slices.Contains(export.Formats, format) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "unsupported format: " + format,
+			"message": "Format must be one of csv, jsonl or xlsx",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("urls-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	writer, err := export.NewWriter(format, c.Writer, urlExportColumns)
+	if err == nil {
+		err = h.URLService.ExportURLs(getURLsParams(c), func(website *models.Website) error {
+			return writer.WriteRow(urlExportRow(website))
+		})
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	// The status was sent with the first rows, so a failure can only cut the file short
+	if err != nil {
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// maxImportFileSize bounds the size of an uploaded import file.
+const maxImportFileSize = 5 << 20
+
+type importURLsRequest struct {
+	SitemapURL string `json:"sitemapUrl" form:"sitemapUrl"`
+	Scan       bool   `json:"scan" form:"scan"`
+	crawlSettingsRequest
+}
+
+// ImportURLs adds the URLs of an uploaded CSV or plain text file, or of a sitemap, and reports
+// the outcome of every row.
+func (h *URLHandler) ImportURLs(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize+1<<20)
+
+	var req importURLsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	entries, err := h.readImportEntries(c, req.SitemapURL)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrTooManyImportRows) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to read URLs to import",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.URLService.ImportURLs(entries, req.toSettings(), req.Scan))
+}
+
+func (h *URLHandler) readImportEntries(c *gin.Context, sitemapURL string) ([]services.ImportEntry, error) {
+	header, err := c.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		if sitemapURL == "" {
+			return nil, errors.New("either a file or a sitemapUrl is required")
+		}
+		return h.URLService.ReadImportSitemap(sitemapURL)
+	} else if err != nil {
+		return nil, err
+	}
+	if header.Size > maxImportFileSize {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxImportFileSize)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(header.Filename), ".csv") || strings.HasPrefix(header.Header.Get("Content-Type"), "text/csv") {
+		return services.ReadImportCSV(file)
+	}
+	return services.ReadImportLines(file)
+}
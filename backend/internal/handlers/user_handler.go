@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"web-crawler/backend/internal/services"
+	"web-crawler/backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type UserHandler struct {
+	UserService *services.UserService
+}
+
+func NewUserHandler(service *services.UserService) *UserHandler {
+	return &UserHandler{UserService: service}
+}
+
+type userRequest struct {
+	Email    string      `json:"email" binding:"required"`
+	Name     string      `json:"name"`
+	Password string      `json:"password"`
+	Role     models.Role `json:"role" binding:"required"`
+	Disabled bool        `json:"disabled"`
+}
+
+func (r userRequest) toInput() services.UserInput {
+	return services.UserInput{Email: r.Email, Name: r.Name, Password: r.Password, Role: r.Role, Disabled: r.Disabled}
+}
+
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	page, limit := getPagination(c)
+	users, totalItems, err := h.UserService.GetUsers(page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve users",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       users,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
+
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.UserService.GetUser(id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req userRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	user, err := h.UserService.CreateUser(req.toInput())
+	if err != nil {
+		h.writeError(c, err, "Failed to create user")
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req userRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	user, err := h.UserService.UpdateUser(id, req.toInput())
+	if err != nil {
+		h.writeError(c, err, "Failed to update user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.UserService.DeleteUser(id); err != nil {
+		h.writeError(c, err, "Failed to delete user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+func (h *UserHandler) writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   err.Error(),
+			"message": "User not found",
+		})
+	case errors.Is(err, services.ErrInvalidUser):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid user",
+		})
+	case errors.Is(err, services.ErrUserExists), errors.Is(err, services.ErrLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   err.Error(),
+			"message": message,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": message,
+		})
+	}
+}
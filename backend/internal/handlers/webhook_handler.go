@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"web-crawler/backend/internal/services"
+	"web-crawler/backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	WebhookService *services.WebhookService
+}
+
+func NewWebhookHandler(service *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{WebhookService: service}
+}
+
+type webhookRequest struct {
+	URL     string   `json:"url" binding:"required"`
+	Secret  string   `json:"secret"`
+	Events  []string `json:"events" binding:"required"`
+	Enabled *bool    `json:"enabled"`
+}
+
+func (r webhookRequest) toInput() services.WebhookInput {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return services.WebhookInput{URL: r.URL, Secret: r.Secret, Events: r.Events, Enabled: enabled}
+}
+
+// webhookWithSecret shows the secret of a webhook, which is only returned when it is created.
+type webhookWithSecret struct {
+	*models.Webhook
+	Secret string `json:"secret"`
+}
+
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	page, limit := getPagination(c)
+	webhooks, totalItems, err := h.WebhookService.GetWebhooks(page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve webhooks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       webhooks,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
+
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.WebhookService.GetWebhook(id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	webhook, err := h.WebhookService.CreateWebhook(req.toInput())
+	if err != nil {
+		h.writeError(c, err, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhookWithSecret{Webhook: webhook, Secret: webhook.Secret})
+}
+
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid request body",
+		})
+		return
+	}
+
+	webhook, err := h.WebhookService.UpdateWebhook(id, req.toInput())
+	if err != nil {
+		h.writeError(c, err, "Failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.WebhookService.DeleteWebhook(id); err != nil {
+		h.writeError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	page, limit := getPagination(c)
+	deliveries, totalItems, err := h.WebhookService.GetDeliveries(id, c.Query("status"), page, limit)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       deliveries,
+		"pagination": paginationResponse(totalItems, page, limit),
+	})
+}
+
+func (h *WebhookHandler) writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   err.Error(),
+			"message": "Webhook not found",
+		})
+	case errors.Is(err, services.ErrInvalidWebhook):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid webhook",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": message,
+		})
+	}
+}
@@ -1,31 +1,69 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
-	"web-crawler/backend/internal/config"
+	"strings"
+	"web-crawler/backend/internal/services"
+	"web-crawler/backend/models"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(cfg config.Config) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		apiKey := c.GetHeader("X-API-Key")
+// userKey holds the authenticated user in the gin context.
+const userKey = "user"
 
-		if cfg.APIKey == "" {
+// AuthMiddleware authenticates a request with a session token, sent as "Authorization: Bearer <token>",
+// and makes the user available through CurrentUser.
+func AuthMiddleware(users *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := users.Authenticate(BearerToken(c))
+		if errors.Is(err, services.ErrInvalidSession) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized: Invalid session",
+				"message": "Please log in and send your session token in the Authorization header.",
+			})
+			return
+		} else if err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-				"error":   "Internal Server Error: API_KEY not configured",
-				"message": "Please set the API_KEY environment variable.",
+				"error":   err.Error(),
+				"message": "Failed to authenticate request",
 			})
 			return
 		}
+		c.Set(userKey, user)
+		c.Next()
+	}
+}
 
-		if apiKey != cfg.APIKey {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error":   "Unauthorized: Invalid API Key",
-				"message": "Please provide a valid API Key in the request header.",
+// RequireRole lets a request through only when its user has at least the given role.
+// It must run after AuthMiddleware.
+func RequireRole(role models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if user == nil || !user.Role.Includes(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden: " + string(role) + " role required",
+				"message": "You are not allowed to perform this action.",
 			})
 			return
 		}
 		c.Next()
 	}
 }
+
+// CurrentUser returns the user authenticated by AuthMiddleware.
+func CurrentUser(c *gin.Context) *models.User {
+	user, _ := c.Get(userKey)
+	u, _ := user.(*models.User)
+	return u
+}
+
+// BearerToken returns the token of an "Authorization: Bearer" header, or an empty string.
+func BearerToken(c *gin.Context) string {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
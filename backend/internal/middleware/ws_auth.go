@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"web-crawler/backend/internal/services"
+	"web-crawler/backend/internal/websocket"
+	"web-crawler/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ticketProtocolPrefix marks a ticket offered as a WebSocket subprotocol, next to websocket.Subprotocol
+const ticketProtocolPrefix = "ticket."
+
+// WebSocketAuthMiddleware authenticates a WebSocket handshake with a ticket from the ticket endpoint,
+// given as a "ticket.<ticket>" subprotocol, or with a session token in an "Authorization: Bearer" header.
+func WebSocketAuthMiddleware(tickets *services.TicketService, users *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorized := false
+		if token := BearerToken(c); token != "" {
+			if _, err := users.Authenticate(token); err == nil {
+				authorized = true
+			}
+		}
+		for _, protocol := range websocket.RequestedSubprotocols(c.Request) {
+			if ticket, ok := strings.CutPrefix(protocol, ticketProtocolPrefix); ok && validTicket(ticket, tickets, users) {
+				authorized = true
+			}
+		}
+
+		if !authorized {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized: Invalid ticket or session",
+				"message": "Please provide a valid WebSocket ticket or session token in the handshake.",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// validTicket reports whether a ticket is valid and its user still exists, is enabled and has
+// the role the ticket was issued for, so that users deleted, disabled or demoted since lose access.
+func validTicket(ticket string, tickets *services.TicketService, users *services.UserService) bool {
+	claims, err := tickets.Verify(ticket)
+	if err != nil {
+		return false
+	}
+	user, err := users.GetUser(int(claims.UserID))
+	return err == nil && !user.Disabled && user.Role == claims.Role && user.Role.Includes(models.RoleViewer)
+}
@@ -6,14 +6,14 @@ import (
 	"web-crawler/backend/internal/handlers"
 	"web-crawler/backend/internal/middleware"
 	"web-crawler/backend/internal/services"
-	"web-crawler/backend/internal/services/crawler"
 	"web-crawler/backend/internal/websocket"
+	"web-crawler/backend/models"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(db *gorm.DB, cfg config.Config, hub *websocket.Hub) *gin.Engine {
+func SetupRoutes(db *gorm.DB, cfg config.Config, hub *websocket.Hub, urlService *services.URLService, scheduleService *services.ScheduleService, webhookService *services.WebhookService, alertService *services.AlertService, userService *services.UserService) *gin.Engine {
 	r := gin.New()
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
@@ -24,25 +24,105 @@ func SetupRoutes(db *gorm.DB, cfg config.Config, hub *websocket.Hub) *gin.Engine
 
 	r.Use(middleware.CORSMiddleware())
 
-	crawlerService := crawler.NewService(db)
-	urlService := services.NewURLService(db, hub, crawlerService)
 	urlHandler := handlers.NewURLHandler(urlService)
+	pageService := services.NewPageService(db)
+	pageHandler := handlers.NewPageHandler(pageService)
+	linkService := services.NewLinkService(db)
+	linkHandler := handlers.NewLinkHandler(linkService)
+	crawlRunService := services.NewCrawlRunService(db)
+	crawlRunHandler := handlers.NewCrawlRunHandler(crawlRunService)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService)
+	ticketService := services.NewTicketService(cfg.WSTicketSecret, cfg.WSTicketTTL)
+	ticketHandler := handlers.NewTicketHandler(ticketService)
+	eventHandler := handlers.NewEventHandler(hub)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	alertHandler := handlers.NewAlertHandler(alertService)
+	projectService := services.NewProjectService(db)
+	projectHandler := handlers.NewProjectHandler(projectService)
+	tagService := services.NewTagService(db)
+	tagHandler := handlers.NewTagHandler(tagService)
+	authHandler := handlers.NewAuthHandler(userService)
+	userHandler := handlers.NewUserHandler(userService)
 
 	api := r.Group("/api/v1")
-	api.Use(middleware.AuthMiddleware(cfg))
+	api.POST("/auth/login", authHandler.Login)
+
+	// Every other route needs a session, and the role noted on its group
+	authed := api.Group("", middleware.AuthMiddleware(userService))
+	authed.GET("/auth/me", authHandler.GetCurrentUser)
+	authed.POST("/auth/logout", authHandler.Logout)
+	authed.PUT("/auth/password", authHandler.ChangePassword)
+
+	viewer := authed.Group("", middleware.RequireRole(models.RoleViewer))
+	{
+		viewer.GET("/urls", urlHandler.GetURLs)
+		viewer.GET("/urls/export", urlHandler.ExportURLs)
+		viewer.GET("/urls/trash", urlHandler.GetTrashedURLs)
+		viewer.GET("/urls/:id", urlHandler.GetURLByID)
+		viewer.GET("/urls/:id/pages", pageHandler.GetPages)
+		viewer.GET("/urls/:id/links", linkHandler.GetLinks)
+		viewer.GET("/urls/:id/sitemap-issues", pageHandler.GetSitemapIssues)
+		viewer.GET("/urls/:id/runs", crawlRunHandler.GetRuns)
+		viewer.GET("/urls/:id/runs/diff", crawlRunHandler.DiffRuns)
+		viewer.GET("/urls/:id/schedule", scheduleHandler.GetSchedule)
+		viewer.GET("/schedules", scheduleHandler.GetSchedules)
+		viewer.POST("/ws/ticket", ticketHandler.IssueWSTicket)
+		viewer.GET("/events", eventHandler.StreamEvents)
+		viewer.GET("/projects", projectHandler.GetProjects)
+		viewer.GET("/projects/:id", projectHandler.GetProject)
+		viewer.GET("/tags", tagHandler.GetTags)
+		viewer.GET("/alerts", alertHandler.GetAlerts)
+		viewer.GET("/alert-rules", alertHandler.GetAlertRules)
+		viewer.GET("/alert-rules/:id", alertHandler.GetAlertRule)
+	}
+
+	editor := authed.Group("", middleware.RequireRole(models.RoleEditor))
+	{
+		editor.POST("/urls", urlHandler.CreateURL)
+		editor.POST("/urls/import", urlHandler.ImportURLs)
+		editor.PATCH("/urls/:id", urlHandler.UpdateURL)
+		editor.PUT("/urls/:id/project", urlHandler.SetURLProject)
+		editor.PUT("/urls/:id/tags", urlHandler.SetURLTags)
+		editor.POST("/urls/:id/schedule", scheduleHandler.CreateSchedule)
+		editor.PUT("/urls/:id/schedule", scheduleHandler.UpdateSchedule)
+		editor.DELETE("/urls/:id/schedule", scheduleHandler.DeleteSchedule)
+		editor.DELETE("/urls/:id", urlHandler.DeleteURLById)
+		editor.POST("/urls/bulk-delete", urlHandler.BulkDeleteURLs)
+		editor.POST("/urls/:id/restore", urlHandler.RestoreURL)
+		editor.POST("/urls/:id/scan", urlHandler.ScanURL)
+		editor.POST("/urls/:id/cancel-scan", urlHandler.CancelScanURL)
+		editor.POST("/urls/:id/pause", urlHandler.PauseScanURL)
+		editor.POST("/urls/:id/resume", urlHandler.ResumeScanURL)
+		editor.POST("/urls/bulk-scan", urlHandler.BulkScanURLs)
+		editor.POST("/projects", projectHandler.CreateProject)
+		editor.PUT("/projects/:id", projectHandler.UpdateProject)
+		editor.DELETE("/projects/:id", projectHandler.DeleteProject)
+		editor.POST("/tags", tagHandler.CreateTag)
+		editor.PUT("/tags/:id", tagHandler.RenameTag)
+		editor.DELETE("/tags/:id", tagHandler.DeleteTag)
+		editor.POST("/alert-rules", alertHandler.CreateAlertRule)
+		editor.PUT("/alert-rules/:id", alertHandler.UpdateAlertRule)
+		editor.DELETE("/alert-rules/:id", alertHandler.DeleteAlertRule)
+	}
+
+	admin := authed.Group("", middleware.RequireRole(models.RoleAdmin))
 	{
-		api.POST("/urls", urlHandler.CreateURL)
-		api.GET("/urls", urlHandler.GetURLs)
-		api.GET("/urls/:id", urlHandler.GetURLByID)
-		api.DELETE("/urls/:id", urlHandler.DeleteURLById)
-		api.POST("/urls/bulk-delete", urlHandler.BulkDeleteURLs)
-		api.POST("/urls/:id/scan", urlHandler.ScanURL)
-		api.POST("/urls/:id/cancel-scan", urlHandler.CancelScanURL)
-		api.POST("/urls/bulk-scan", urlHandler.BulkScanURLs)
+		admin.DELETE("/urls/:id/purge", urlHandler.PurgeURL)
+		admin.GET("/webhooks", webhookHandler.GetWebhooks)
+		admin.POST("/webhooks", webhookHandler.CreateWebhook)
+		admin.GET("/webhooks/:id", webhookHandler.GetWebhook)
+		admin.PUT("/webhooks/:id", webhookHandler.UpdateWebhook)
+		admin.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+		admin.GET("/webhooks/:id/deliveries", webhookHandler.GetDeliveries)
+		admin.GET("/users", userHandler.GetUsers)
+		admin.POST("/users", userHandler.CreateUser)
+		admin.GET("/users/:id", userHandler.GetUser)
+		admin.PUT("/users/:id", userHandler.UpdateUser)
+		admin.DELETE("/users/:id", userHandler.DeleteUser)
 	}
 
-	r.GET("/ws", func(c *gin.Context) {
-		websocket.ServeWs(hub, c.Writer, c.Request)
+	r.GET("/ws", middleware.WebSocketAuthMiddleware(ticketService, userService), func(c *gin.Context) {
+		websocket.ServeWs(hub, cfg.WSAllowedOrigins, c.Writer, c.Request)
 	})
 
 	return r
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+	"web-crawler/backend/internal/websocket"
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidAlertRule = errors.New("invalid alert rule")
+
+// OperatorChanged matches when a metric differs from the previous run.
+const OperatorChanged = "changed"
+
+type metricKind int
+
+const (
+	numericMetric metricKind = iota
+	stringMetric
+	boolMetric
+)
+
+// alertMetrics are the metrics rules can test. Only status and consecutiveFailures are
+// evaluated after failed scans, since the other metrics are incomplete then.
+var alertMetrics = map[string]metricKind{
+	"brokenLinks":         numericMetric,
+	"internalLinks":       numericMetric,
+	"externalLinks":       numericMetric,
+	"pagesCrawled":        numericMetric,
+	"robotsSkipped":       numericMetric,
+	"orphanPages":         numericMetric,
+	"unlistedPages":       numericMetric,
+	"consecutiveFailures": numericMetric,
+	"htmlVersion":         stringMetric,
+	"title":               stringMetric,
+	"status":              stringMetric,
+	"hasLoginForm":        boolMetric,
+}
+
+var alertOperators = map[metricKind][]string{
+	numericMetric: {">", ">=", "<", "<=", "==", "!=", OperatorChanged},
+	stringMetric:  {"==", "!=", OperatorChanged},
+	boolMetric:    {"==", "!=", OperatorChanged},
+}
+
+type AlertService struct {
+	DB  *gorm.DB
+	Hub *websocket.Hub
+}
+
+func NewAlertService(db *gorm.DB, hub *websocket.Hub) *AlertService {
+	return &AlertService{DB: db, Hub: hub}
+}
+
+// AlertRuleInput describes an alert rule. A nil WebsiteID makes the rule global.
+type AlertRuleInput struct {
+	Name      string
+	WebsiteID *uint
+	Metric    string
+	Operator  string
+	Value     string
+	Enabled   bool
+}
+
+func (in AlertRuleInput) apply(rule *models.AlertRule) error {
+	kind, ok := alertMetrics[in.Metric]
+	if !ok {
+		return fmt.Errorf("%w: unknown metric %q", ErrInvalidAlertRule, in.Metric)
+	}
+	if !slices.Contains(alertOperators[kind], in.Operator) {
+		return fmt.Errorf("%w: operator %q does not apply to %s", ErrInvalidAlertRule, in.Operator, in.Metric)
+	}
+
+	value := in.Value
+	switch {
+	case in.Operator == OperatorChanged:
+		value = ""
+	case kind == numericMetric:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%w: value of %s must be an integer", ErrInvalidAlertRule, in.Metric)
+		}
+	case kind == boolMetric:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%w: value of %s must be true or false", ErrInvalidAlertRule, in.Metric)
+		}
+		value = strconv.FormatBool(parsed)
+	}
+
+	rule.Name = in.Name
+	rule.WebsiteID = in.WebsiteID
+	rule.Metric = in.Metric
+	rule.Operator = in.Operator
+	rule.Value = value
+	rule.Enabled = in.Enabled
+	return nil
+}
+
+func (s *AlertService) GetAlertRules(page, limit int) ([]models.AlertRule, int64, error) {
+	query := s.DB.Model(&models.AlertRule{})
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	var rules []models.AlertRule
+	if err := query.Order("id desc").Offset(offset).Limit(limit).Find(&rules).Error; err != nil {
+		return nil, 0, err
+	}
+	return rules, totalItems, nil
+}
+
+func (s *AlertService) GetAlertRule(id int) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	if err := s.DB.First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (s *AlertService) CreateAlertRule(input AlertRuleInput) (*models.AlertRule, error) {
+	if err := s.checkWebsite(input.WebsiteID); err != nil {
+		return nil, err
+	}
+	var rule models.AlertRule
+	if err := input.apply(&rule); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Create(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdateAlertRule replaces an alert rule. Its alerts are resolved by the next scan if the new
+// condition no longer holds.
+func (s *AlertService) UpdateAlertRule(id int, input AlertRuleInput) (*models.AlertRule, error) {
+	rule, err := s.GetAlertRule(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWebsite(input.WebsiteID); err != nil {
+		return nil, err
+	}
+	if err := input.apply(rule); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Save(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteAlertRule deletes an alert rule along with its alerts.
+func (s *AlertService) DeleteAlertRule(id int) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.AlertRule{}, id)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("alert_rule_id = ?", id).Delete(&models.Alert{}).Error
+	})
+}
+
+func (s *AlertService) checkWebsite(websiteID *uint) error {
+	if websiteID == nil {
+		return nil
+	}
+	if *websiteID == 0 {
+		return fmt.Errorf("%w: websiteId must be positive", ErrInvalidAlertRule)
+	}
+	return s.DB.Select("id").First(&models.Website{}, *websiteID).Error
+}
+
+type GetAlertsParams struct {
+	Page      int
+	Limit     int
+	State     string
+	WebsiteID string
+	RuleID    string
+}
+
+// GetAlerts returns alerts with their rule, newest first.
+func (s *AlertService) GetAlerts(params GetAlertsParams) ([]models.Alert, int64, error) {
+	query := s.DB.Model(&models.Alert{})
+	if params.State != "" {
+		query = query.Where("state = ?", params.State)
+	}
+	if params.WebsiteID != "" {
+		query = query.Where("website_id = ?", params.WebsiteID)
+	}
+	if params.RuleID != "" {
+		query = query.Where("alert_rule_id = ?", params.RuleID)
+	}
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	var alerts []models.Alert
+	err := query.Preload("AlertRule").Order("id desc").Offset(offset).Limit(params.Limit).Find(&alerts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return alerts, totalItems, nil
+}
+
+// scanFacts gives the values rules are evaluated on for a finished run,
+// loading those that need earlier runs only when a rule asks for them.
+type scanFacts struct {
+	db  *gorm.DB
+	run *models.CrawlRun
+
+	previous            map[bool]*models.CrawlRun
+	consecutiveFailures *int
+}
+
+// value returns the current value of a metric.
+func (f *scanFacts) value(metric string) (string, error) {
+	if metric == "consecutiveFailures" {
+		count, err := f.countFailures()
+		return strconv.Itoa(count), err
+	}
+	return metricValue(f.run, metric), nil
+}
+
+// previousValue returns the value of a metric in the previous run, or false when there is none.
+// Status is compared with the previous completed or failed run, other metrics with the previous completed run.
+func (f *scanFacts) previousValue(metric string) (string, bool, error) {
+	if metric == "consecutiveFailures" {
+		return "", false, nil
+	}
+
+	completedOnly := metric != "status"
+	previous, ok := f.previous[completedOnly]
+	if !ok {
+		statuses := []models.StatusType{models.Completed}
+		if !completedOnly {
+			statuses = append(statuses, models.Failed)
+		}
+		var runs []models.CrawlRun
+		err := f.db.Where("website_id = ? AND status IN ? AND id < ?", f.run.WebsiteID, statuses, f.run.ID).
+			Order("id desc").Limit(1).Find(&runs).Error
+		if err != nil {
+			return "", false, err
+		}
+		if len(runs) > 0 {
+			previous = &runs[0]
+		}
+		f.previous[completedOnly] = previous
+	}
+	if previous == nil {
+		return "", false, nil
+	}
+	return metricValue(previous, metric), true, nil
+}
+
+// countFailures returns the number of failed runs in a row up to the current one. Cancelled runs are skipped.
+func (f *scanFacts) countFailures() (int, error) {
+	if f.consecutiveFailures != nil {
+		return *f.consecutiveFailures, nil
+	}
+
+	var statuses []models.StatusType
+	err := f.db.Model(&models.CrawlRun{}).
+		Where("website_id = ? AND status IN ? AND id <= ?", f.run.WebsiteID, []models.StatusType{models.Completed, models.Failed}, f.run.ID).
+		Order("id desc").Limit(100).Pluck("status", &statuses).Error
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for count < len(statuses) && statuses[count] == models.Failed {
+		count++
+	}
+	f.consecutiveFailures = &count
+	return count, nil
+}
+
+func metricValue(run *models.CrawlRun, metric string) string {
+	switch metric {
+	case "brokenLinks":
+		return strconv.Itoa(run.BrokenLinks)
+	case "internalLinks":
+		return strconv.Itoa(run.InternalLinks)
+	case "externalLinks":
+		return strconv.Itoa(run.ExternalLinks)
+	case "pagesCrawled":
+		return strconv.Itoa(run.PagesCrawled)
+	case "robotsSkipped":
+		return strconv.Itoa(run.RobotsSkipped)
+	case "orphanPages":
+		return strconv.Itoa(run.OrphanPages)
+	case "unlistedPages":
+		return strconv.Itoa(run.UnlistedPages)
+	case "htmlVersion":
+		return run.HTMLVersion
+	case "title":
+		return run.Title
+	case "status":
+		return string(run.Status)
+	case "hasLoginForm":
+		return strconv.FormatBool(run.HasLoginForm)
+	}
+	return ""
+}
+
+// matches evaluates a rule on a finished run. It returns whether the condition holds,
+// with a message describing what was observed.
+func (f *scanFacts) matches(rule *models.AlertRule) (bool, string, error) {
+	current, err := f.value(rule.Metric)
+	if err != nil {
+		return false, "", err
+	}
+
+	if rule.Operator == OperatorChanged {
+		previous, ok, err := f.previousValue(rule.Metric)
+		if err != nil || !ok || previous == current {
+			return false, "", err
+		}
+		return true, fmt.Sprintf("%s changed from %q to %q", rule.Metric, previous, current), nil
+	}
+
+	message := fmt.Sprintf("%s %s %s (value %s)", rule.Metric, rule.Operator, rule.Value, current)
+	if alertMetrics[rule.Metric] != numericMetric {
+		equal := current == rule.Value
+		return equal == (rule.Operator == "=="), message, nil
+	}
+
+	// A value that cannot be read leaves the alert as it is rather than comparing it as 0
+	value, err := strconv.Atoi(current)
+	if err != nil {
+		return false, "", fmt.Errorf("reading %s: %w", rule.Metric, err)
+	}
+	threshold, err := strconv.Atoi(rule.Value)
+	if err != nil {
+		return false, "", err
+	}
+	switch rule.Operator {
+	case ">":
+		return value > threshold, message, nil
+	case ">=":
+		return value >= threshold, message, nil
+	case "<":
+		return value < threshold, message, nil
+	case "<=":
+		return value <= threshold, message, nil
+	case "==":
+		return value == threshold, message, nil
+	default:
+		return value != threshold, message, nil
+	}
+}
+
+// EvaluateScan evaluates the alert rules of a website on its finished crawl run, firing alerts whose
+// condition now holds and resolving those whose condition no longer does. Changes are pushed over the hub.
+func (s *AlertService) EvaluateScan(website *models.Website, run *models.CrawlRun) {
+	if run.Status != models.Completed && run.Status != models.Failed {
+		return
+	}
+
+	var rules []models.AlertRule
+	err := s.DB.Where("enabled = ? AND (website_id IS NULL OR website_id = ?)", true, website.ID).Find(&rules).Error
+	if err != nil {
+		fmt.Println("Error loading alert rules:", err)
+		return
+	}
+
+	facts := &scanFacts{db: s.DB, run: run, previous: make(map[bool]*models.CrawlRun)}
+	for i := range rules {
+		rule := &rules[i]
+		if run.Status == models.Failed && rule.Metric != "status" && rule.Metric != "consecutiveFailures" {
+			continue
+		}
+
+		matched, message, err := facts.matches(rule)
+		if err != nil {
+			fmt.Println("Error evaluating alert rule:", err)
+			continue
+		}
+		if err := s.updateAlert(rule, website.ID, run.ID, matched, message); err != nil {
+			fmt.Println("Error updating alert:", err)
+		}
+	}
+}
+
+// updateAlert fires or resolves the alert of a rule for a website.
+func (s *AlertService) updateAlert(rule *models.AlertRule, websiteID, runID uint, matched bool, message string) error {
+	var alerts []models.Alert
+	err := s.DB.Where("alert_rule_id = ? AND website_id = ? AND state = ?", rule.ID, websiteID, models.AlertFiring).
+		Limit(1).Find(&alerts).Error
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	switch {
+	case matched && len(alerts) == 0:
+		alert := models.Alert{
+			AlertRuleID: rule.ID,
+			WebsiteID:   websiteID,
+			State:       models.AlertFiring,
+			Message:     message,
+			CrawlRunID:  runID,
+			FiredAt:     now,
+		}
+		if err := s.DB.Create(&alert).Error; err != nil {
+			return err
+		}
+		alert.AlertRule = rule
+		s.Hub.Publish(websocket.NewEvent(websocket.EventAlert, websiteID, alert))
+	case matched:
+		// Still firing, keep the latest observation
+		return s.DB.Model(&alerts[0]).Updates(map[string]any{"message": message, "crawl_run_id": runID}).Error
+	case len(alerts) > 0:
+		alert := alerts[0]
+		alert.State = models.AlertResolved
+		alert.ResolvedAt = &now
+		alert.CrawlRunID = runID
+		if err := s.DB.Save(&alert).Error; err != nil {
+			return err
+		}
+		alert.AlertRule = rule
+		s.Hub.Publish(websocket.NewEvent(websocket.EventAlert, websiteID, alert))
+	}
+	return nil
+}
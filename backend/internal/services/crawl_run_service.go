@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRunNotFound = errors.New("crawl run not found")
+)
+
+type CrawlRunService struct {
+	DB *gorm.DB
+}
+
+func NewCrawlRunService(db *gorm.DB) *CrawlRunService {
+	return &CrawlRunService{DB: db}
+}
+
+// GetRuns returns the crawl runs of a website, newest first.
+func (s *CrawlRunService) GetRuns(websiteID, page, limit int) ([]models.CrawlRun, int64, error) {
+	if err := s.DB.Select("id").First(&models.Website{}, websiteID).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := s.DB.Model(&models.CrawlRun{}).Where("website_id = ?", websiteID)
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	var runs []models.CrawlRun
+	if result := query.Order("id desc").Offset(offset).Limit(limit).Find(&runs); result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return runs, totalItems, nil
+}
+
+// resolveRunID returns the crawl run whose results should be read: the requested run when
+// one is given, otherwise the latest run of the website. It returns 0 when the website was never crawled.
+func resolveRunID(db *gorm.DB, websiteID int, requested string) (uint, error) {
+	if requested != "" {
+		runID, err := strconv.Atoi(requested)
+		if err != nil || runID <= 0 {
+			return 0, gorm.ErrRecordNotFound
+		}
+		var run models.CrawlRun
+		if err := db.Select("id").Where("website_id = ?", websiteID).First(&run, runID).Error; err != nil {
+			return 0, err
+		}
+		return run.ID, nil
+	}
+
+	var runs []models.CrawlRun
+	if err := db.Select("id").Where("website_id = ?", websiteID).Order("id desc").Limit(1).Find(&runs).Error; err != nil {
+		return 0, err
+	}
+	if len(runs) == 0 {
+		return 0, nil
+	}
+	return runs[0].ID, nil
+}
+
+// ValueChange describes how a single value differs between two crawl runs.
+type ValueChange[T comparable] struct {
+	From    T    `json:"from"`
+	To      T    `json:"to"`
+	Changed bool `json:"changed"`
+}
+
+func newValueChange[T comparable](from, to T) ValueChange[T] {
+	return ValueChange[T]{From: from, To: to, Changed: from != to}
+}
+
+// CountChange describes how a count differs between two crawl runs.
+type CountChange struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Delta int `json:"delta"`
+}
+
+func newCountChange(from, to int) CountChange {
+	return CountChange{From: from, To: to, Delta: to - from}
+}
+
+// BrokenLinkChange is a link target that was working in the first run and broken in the second.
+type BrokenLinkChange struct {
+	TargetURL  string `json:"targetUrl"`
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error,omitempty"`
+}
+
+// CrawlRunDiff lists what changed between two crawl runs of the same website.
+type CrawlRunDiff struct {
+	From          models.CrawlRun        `json:"from"`
+	To            models.CrawlRun        `json:"to"`
+	Title         ValueChange[string]    `json:"title"`
+	HTMLVersion   ValueChange[string]    `json:"htmlVersion"`
+	HasLoginForm  ValueChange[bool]      `json:"hasLoginForm"`
+	Headings      map[string]CountChange `json:"headings"`
+	BrokenLinks   CountChange            `json:"brokenLinks"`
+	LinksAdded    []string               `json:"linksAdded"`
+	LinksRemoved  []string               `json:"linksRemoved"`
+	NewlyBroken   []BrokenLinkChange     `json:"newlyBroken"`
+	PagesCrawled  CountChange            `json:"pagesCrawled"`
+	InternalLinks CountChange            `json:"internalLinks"`
+	ExternalLinks CountChange            `json:"externalLinks"`
+}
+
+// DiffRuns compares two crawl runs of a website. When to is empty the latest run is used,
+// and when from is empty the last completed run preceding to is used, so that runs that failed,
+// were cancelled or are still running do not show every link and metric as changed.
+func (s *CrawlRunService) DiffRuns(websiteID int, from, to string) (*CrawlRunDiff, error) {
+	if err := s.DB.Select("id").First(&models.Website{}, websiteID).Error; err != nil {
+		return nil, err
+	}
+
+	toID, err := resolveRunID(s.DB, websiteID, to)
+	if err != nil {
+		return nil, err
+	}
+	if toID == 0 {
+		return nil, ErrRunNotFound
+	}
+	var toRun models.CrawlRun
+	if err := s.DB.First(&toRun, toID).Error; err != nil {
+		return nil, err
+	}
+
+	var fromRun models.CrawlRun
+	if from != "" {
+		fromID, err := resolveRunID(s.DB, websiteID, from)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.DB.First(&fromRun, fromID).Error; err != nil {
+			return nil, err
+		}
+	} else {
+		var previous []models.CrawlRun
+		if err := s.DB.Where("website_id = ? AND status = ? AND id < ?", websiteID, models.Completed, toRun.ID).Order("id desc").Limit(1).Find(&previous).Error; err != nil {
+			return nil, err
+		}
+		if len(previous) == 0 {
+			return nil, ErrRunNotFound
+		}
+		fromRun = previous[0]
+	}
+
+	fromLinks, err := s.runLinks(fromRun.ID)
+	if err != nil {
+		return nil, err
+	}
+	toLinks, err := s.runLinks(toRun.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &CrawlRunDiff{
+		From:          fromRun,
+		To:            toRun,
+		Title:         newValueChange(fromRun.Title, toRun.Title),
+		HTMLVersion:   newValueChange(fromRun.HTMLVersion, toRun.HTMLVersion),
+		HasLoginForm:  newValueChange(fromRun.HasLoginForm, toRun.HasLoginForm),
+		Headings:      make(map[string]CountChange),
+		BrokenLinks:   newCountChange(fromRun.BrokenLinks, toRun.BrokenLinks),
+		PagesCrawled:  newCountChange(fromRun.PagesCrawled, toRun.PagesCrawled),
+		InternalLinks: newCountChange(fromRun.InternalLinks, toRun.InternalLinks),
+		ExternalLinks: newCountChange(fromRun.ExternalLinks, toRun.ExternalLinks),
+		LinksAdded:    []string{},
+		LinksRemoved:  []string{},
+		NewlyBroken:   []BrokenLinkChange{},
+	}
+
+	for tag, count := range fromRun.HeadingsCount {
+		diff.Headings[tag] = newCountChange(count, toRun.HeadingsCount[tag])
+	}
+	for tag, count := range toRun.HeadingsCount {
+		if _, ok := diff.Headings[tag]; !ok {
+			diff.Headings[tag] = newCountChange(0, count)
+		}
+	}
+
+	for target, link := range toLinks {
+		previous, existed := fromLinks[target]
+		if !existed {
+			diff.LinksAdded = append(diff.LinksAdded, target)
+		}
+		if link.Broken && (!existed || !previous.Broken) {
+			diff.NewlyBroken = append(diff.NewlyBroken, BrokenLinkChange{
+				TargetURL:  target,
+				StatusCode: link.StatusCode,
+				Error:      link.Error,
+			})
+		}
+	}
+	for target := range fromLinks {
+		if _, ok := toLinks[target]; !ok {
+			diff.LinksRemoved = append(diff.LinksRemoved, target)
+		}
+	}
+
+	sort.Strings(diff.LinksAdded)
+	sort.Strings(diff.LinksRemoved)
+	sort.Slice(diff.NewlyBroken, func(i, j int) bool {
+		return diff.NewlyBroken[i].TargetURL < diff.NewlyBroken[j].TargetURL
+	})
+
+	return diff, nil
+}
+
+// runLinks returns the distinct link targets of a crawl run. A target is broken
+// if any of the links pointing to it was found broken.
+func (s *CrawlRunService) runLinks(runID uint) (map[string]models.Link, error) {
+	var links []models.Link
+	if err := s.DB.Select("target_url", "status_code", "broken", "error").Where("crawl_run_id = ?", runID).Find(&links).Error; err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]models.Link, len(links))
+	for _, link := range links {
+		if existing, ok := targets[link.TargetURL]; ok && (existing.Broken || !link.Broken) {
+			continue
+		}
+		targets[link.TargetURL] = link
+	}
+	return targets, nil
+}
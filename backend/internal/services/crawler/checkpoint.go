@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"encoding/json"
+	"errors"
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+)
+
+// frontierEntry is a page that was discovered but not fetched before the crawl was paused.
+type frontierEntry struct {
+	URL   string `json:"url"`
+	Depth int    `json:"depth"`
+	Seed  bool   `json:"seed"`
+}
+
+// checkpointState is everything needed to resume a paused crawl: the pages still to fetch,
+// the pages already done, the links whose check did not finish and the totals so far.
+type checkpointState struct {
+	Frontier       []frontierEntry   `json:"frontier"`
+	Visited        []string          `json:"visited"`
+	PendingLinks   []models.Link     `json:"pendingLinks"`
+	SitemapURLs    []string          `json:"sitemapUrls"`
+	Linked         map[string]string `json:"linked"`
+	PagesRequested int               `json:"pagesRequested"`
+	PagesFetched   int               `json:"pagesFetched"`
+	LinksChecked   int               `json:"linksChecked"`
+
+	PageTitle     string         `json:"pageTitle"`
+	HTMLVersion   string         `json:"htmlVersion"`
+	Headings      map[string]int `json:"headings"`
+	InternalLinks int            `json:"internalLinks"`
+	ExternalLinks int            `json:"externalLinks"`
+	HasLoginForm  bool           `json:"hasLoginForm"`
+	PagesCrawled  int            `json:"pagesCrawled"`
+	RobotsSkipped int            `json:"robotsSkipped"`
+	BrokenLinks   int            `json:"brokenLinks"`
+}
+
+// loadCheckpoint returns the saved state of a paused crawl run, or nil when the run was never paused.
+func (s *Service) loadCheckpoint(runID uint) (*checkpointState, error) {
+	var checkpoint models.CrawlCheckpoint
+	if err := s.DB.Where("crawl_run_id = ?", runID).First(&checkpoint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var state checkpointState
+	if err := json.Unmarshal([]byte(checkpoint.State), &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// saveCheckpoint replaces the saved state of a crawl run.
+func (s *Service) saveCheckpoint(websiteID, runID uint, state *checkpointState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("crawl_run_id = ?", runID).Delete(&models.CrawlCheckpoint{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.CrawlCheckpoint{WebsiteID: websiteID, CrawlRunID: runID, State: string(data)}).Error
+	})
+}
+
+// DiscardCheckpoint deletes the saved state of a paused crawl run that will not be resumed.
+func (s *Service) DiscardCheckpoint(runID uint) error {
+	return s.DB.Unscoped().Where("crawl_run_id = ?", runID).Delete(&models.CrawlCheckpoint{}).Error
+}
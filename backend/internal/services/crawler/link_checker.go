@@ -0,0 +1,213 @@
+package crawler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"web-crawler/backend/internal/types"
+	"web-crawler/backend/models"
+)
+
+const maxRedirects = 10
+
+// linkStatus is the outcome of checking a link target.
+type linkStatus struct {
+	statusCode int
+	err        error
+	hops       types.RedirectHops
+	skipped    bool
+	// aborted is set for checks that were not run because the crawl was paused
+	aborted bool
+}
+
+func (ls linkStatus) broken() bool {
+	if ls.skipped || ls.aborted {
+		return false
+	}
+	return ls.err != nil || (ls.statusCode >= 400 && ls.statusCode < 600)
+}
+
+func (ls linkStatus) applyTo(link *models.Link) {
+	link.StatusCode = ls.statusCode
+	link.Broken = ls.broken()
+	link.RedirectHops = ls.hops
+	if ls.err != nil {
+		link.Error = ls.err.Error()
+	}
+}
+
+// linkCheck is a single target URL check shared by every link pointing to it.
+type linkCheck struct {
+	target  string
+	done    bool
+	status  linkStatus
+	waiters []func(linkStatus)
+}
+
+// linkChecker verifies link targets for a single crawl. Each target is checked at most
+// once, by a fixed pool of workers, with a cap on concurrent requests per host.
+type linkChecker struct {
+	client    *http.Client
+	userAgent string
+	perHost   int
+
+	jobs    chan *linkCheck
+	workers sync.WaitGroup
+	pending sync.WaitGroup
+	stopped atomic.Bool
+
+	mu    sync.Mutex
+	cache map[string]*linkCheck
+	hosts map[string]chan struct{}
+}
+
+func newLinkChecker(client *http.Client, userAgent string, concurrency, perHost int) *linkChecker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if perHost < 1 {
+		perHost = 1
+	}
+
+	lc := &linkChecker{
+		client:    client,
+		userAgent: userAgent,
+		perHost:   perHost,
+		jobs:      make(chan *linkCheck, concurrency),
+		cache:     make(map[string]*linkCheck),
+		hosts:     make(map[string]chan struct{}),
+	}
+	lc.workers.Add(concurrency)
+	for range concurrency {
+		go lc.work()
+	}
+	return lc
+}
+
+// Check schedules a check of target and calls onDone with the result once it has settled.
+// onDone is called synchronously when the target was already checked during this crawl.
+func (lc *linkChecker) Check(target string, onDone func(linkStatus)) {
+	lc.mu.Lock()
+	if check, ok := lc.cache[target]; ok {
+		if check.done {
+			lc.mu.Unlock()
+			onDone(check.status)
+			return
+		}
+		check.waiters = append(check.waiters, onDone)
+		lc.mu.Unlock()
+		return
+	}
+
+	check := &linkCheck{target: target, waiters: []func(linkStatus){onDone}}
+	lc.cache[target] = check
+	lc.pending.Add(1)
+	lc.mu.Unlock()
+
+	lc.jobs <- check
+}
+
+// Wait blocks until every scheduled check has settled and stops the workers.
+// No checks may be scheduled after Wait is called.
+func (lc *linkChecker) Wait() {
+	lc.pending.Wait()
+	close(lc.jobs)
+	lc.workers.Wait()
+}
+
+// Stop settles every check that has not started yet as aborted, without requesting its target.
+func (lc *linkChecker) Stop() {
+	lc.stopped.Store(true)
+}
+
+func (lc *linkChecker) work() {
+	defer lc.workers.Done()
+	for check := range lc.jobs {
+		status := lc.run(check.target)
+
+		lc.mu.Lock()
+		check.done = true
+		check.status = status
+		waiters := check.waiters
+		check.waiters = nil
+		lc.mu.Unlock()
+
+		for _, onDone := range waiters {
+			onDone(status)
+		}
+		lc.pending.Done()
+	}
+}
+
+func (lc *linkChecker) run(target string) linkStatus {
+	if lc.stopped.Load() {
+		return linkStatus{aborted: true}
+	}
+
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return linkStatus{err: err}
+	}
+	// mailto:, tel:, javascript: and similar links cannot be checked over HTTP
+	if targetURL.Scheme != "http" && targetURL.Scheme != "https" {
+		return linkStatus{skipped: true}
+	}
+
+	release := lc.acquireHost(targetURL.Host)
+	defer release()
+
+	status := lc.request(http.MethodHead, target)
+	// Some servers reject HEAD requests outright, retry those with a GET
+	if status.err == nil && (status.statusCode == http.StatusMethodNotAllowed || status.statusCode == http.StatusNotImplemented) {
+		status = lc.request(http.MethodGet, target)
+	}
+	return status
+}
+
+// acquireHost blocks until a request slot for host is available and returns its release function.
+func (lc *linkChecker) acquireHost(host string) func() {
+	lc.mu.Lock()
+	slots, ok := lc.hosts[host]
+	if !ok {
+		slots = make(chan struct{}, lc.perHost)
+		lc.hosts[host] = slots
+	}
+	lc.mu.Unlock()
+
+	slots <- struct{}{}
+	return func() { <-slots }
+}
+
+func (lc *linkChecker) request(method, target string) linkStatus {
+	var status linkStatus
+	client := *lc.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errors.New("stopped after 10 redirects")
+		}
+		status.hops = append(status.hops, types.RedirectHop{
+			URL:        via[len(via)-1].URL.String(),
+			StatusCode: req.Response.StatusCode,
+		})
+		return nil
+	}
+
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		status.err = err
+		return status
+	}
+	req.Header.Set("User-Agent", lc.userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		status.err = err
+		return status
+	}
+	defer resp.Body.Close()
+
+	status.statusCode = resp.StatusCode
+	return status
+}
@@ -0,0 +1,47 @@
+package crawler
+
+import "time"
+
+// Progress is a snapshot of a running crawl, reported periodically while ProcessURL runs.
+type Progress struct {
+	PagesFetched int   `json:"pagesFetched"`
+	PagesQueued  int   `json:"pagesQueued"`
+	MaxPages     int   `json:"maxPages"`
+	LinksChecked int   `json:"linksChecked"`
+	BrokenLinks  int   `json:"brokenLinks"`
+	ElapsedMs    int64 `json:"elapsedMs"`
+}
+
+// reportProgress calls report with a fresh snapshot at most once per interval, skipping
+// snapshots where nothing but the elapsed time changed, until done is closed.
+// A last snapshot is reported when done is closed.
+func reportProgress(interval time.Duration, snapshot func() Progress, report func(Progress), done <-chan struct{}) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last Progress
+	emit := func() {
+		progress := snapshot()
+		elapsed := progress.ElapsedMs
+		progress.ElapsedMs = last.ElapsedMs
+		if progress == last {
+			return
+		}
+		progress.ElapsedMs = elapsed
+		last = progress
+		report(progress)
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			emit()
+		case <-done:
+			emit()
+			return
+		}
+	}
+}
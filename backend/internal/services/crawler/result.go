@@ -0,0 +1,301 @@
+package crawler
+
+import (
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"web-crawler/backend/internal/urlnorm"
+	"web-crawler/backend/models"
+
+	"github.com/gocolly/colly"
+)
+
+// pageResult accumulates the analysis of a single fetched page. It is only
+// touched by the goroutine handling that page's response.
+type pageResult struct {
+	url           string
+	depth         int
+	start         bool
+	statusCode    int
+	err           string
+	skipReason    string
+	htmlVersion   string
+	title         string
+	headings      map[string]int
+	internalLinks int
+	externalLinks int
+	hasLoginForm  bool
+}
+
+func (p *pageResult) toModel(websiteID, runID uint) models.Page {
+	return models.Page{
+		WebsiteID:     websiteID,
+		CrawlRunID:    runID,
+		URL:           p.url,
+		Depth:         p.depth,
+		StatusCode:    p.statusCode,
+		Error:         p.err,
+		SkipReason:    p.skipReason,
+		HTMLVersion:   p.htmlVersion,
+		Title:         p.title,
+		HeadingsCount: p.headings,
+		InternalLinks: p.internalLinks,
+		ExternalLinks: p.externalLinks,
+		HasLoginForm:  p.hasLoginForm,
+	}
+}
+
+// crawlResult aggregates the per-page results of a whole site crawl.
+type crawlResult struct {
+	mu             sync.Mutex
+	normalizer     *urlnorm.Normalizer
+	pages          map[uint32]*pageResult
+	linked         map[string]string
+	pageTitle      string
+	htmlVersion    string
+	headings       map[string]int
+	internalLinks  int
+	externalLinks  int
+	hasLoginForm   bool
+	pagesCrawled   int
+	robotsSkipped  int
+	brokenLinks    int32
+	linksChecked   int32
+	crawlFailed    int32
+	failure        *CrawlError
+	crawlCancelled int32
+
+	// Progress kept for pausing: pages requested so far, and pages and link checks left for a resume
+	requested    map[string]bool
+	frontier     []frontierEntry
+	pendingLinks []models.Link
+}
+
+func newCrawlResult(normalizer *urlnorm.Normalizer) *crawlResult {
+	return &crawlResult{
+		normalizer: normalizer,
+		pages:      make(map[uint32]*pageResult),
+		linked:     make(map[string]string),
+		headings:   make(map[string]int),
+		requested:  make(map[string]bool),
+	}
+}
+
+// restore continues from the totals of a paused crawl.
+func (r *crawlResult) restore(state *checkpointState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, v := range state.Linked {
+		r.linked[k] = v
+	}
+	for k, v := range state.Headings {
+		r.headings[k] = v
+	}
+	r.pageTitle = state.PageTitle
+	r.htmlVersion = state.HTMLVersion
+	r.internalLinks = state.InternalLinks
+	r.externalLinks = state.ExternalLinks
+	r.hasLoginForm = state.HasLoginForm
+	r.pagesCrawled = state.PagesCrawled
+	r.robotsSkipped = state.RobotsSkipped
+	atomic.StoreInt32(&r.brokenLinks, int32(state.BrokenLinks))
+	atomic.StoreInt32(&r.linksChecked, int32(state.LinksChecked))
+}
+
+// checkpoint captures the progress of a paused crawl. visited holds the pages done before a previous pause.
+func (r *crawlResult) checkpoint(visited map[string]bool, sitemapURLs []string, pagesRequested, pagesFetched int) *checkpointState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := &checkpointState{
+		Frontier:       r.frontier,
+		PendingLinks:   r.pendingLinks,
+		SitemapURLs:    sitemapURLs,
+		Linked:         r.linked,
+		PagesRequested: pagesRequested,
+		PagesFetched:   pagesFetched,
+		LinksChecked:   int(atomic.LoadInt32(&r.linksChecked)),
+		PageTitle:      r.pageTitle,
+		HTMLVersion:    r.htmlVersion,
+		Headings:       r.headings,
+		InternalLinks:  r.internalLinks,
+		ExternalLinks:  r.externalLinks,
+		HasLoginForm:   r.hasLoginForm,
+		PagesCrawled:   r.pagesCrawled,
+		RobotsSkipped:  r.robotsSkipped,
+		BrokenLinks:    int(atomic.LoadInt32(&r.brokenLinks)),
+	}
+	for u := range visited {
+		state.Visited = append(state.Visited, u)
+	}
+	for u := range r.requested {
+		state.Visited = append(state.Visited, u)
+	}
+	return state
+}
+
+// markRequested records that the page with the normalized URL key was fetched, or skipped, and must
+// not be fetched again in this crawl or on resume. It returns false when the page was already requested.
+func (r *crawlResult) markRequested(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.requested[key] {
+		return false
+	}
+	r.requested[key] = true
+	return true
+}
+
+// deferRequest puts a page that was not fetched because the crawl was paused on the frontier.
+func (r *crawlResult) deferRequest(pageURL string, depth int, seed bool) {
+	r.mu.Lock()
+	if u, err := url.Parse(pageURL); err == nil {
+		delete(r.requested, r.normalizer.String(u))
+	}
+	r.frontier = append(r.frontier, frontierEntry{URL: pageURL, Depth: depth, Seed: seed})
+	r.mu.Unlock()
+}
+
+// deferLink keeps a link whose check was interrupted by a pause, to check it on resume.
+func (r *crawlResult) deferLink(link models.Link) {
+	r.mu.Lock()
+	r.pendingLinks = append(r.pendingLinks, link)
+	r.mu.Unlock()
+}
+
+// hasDeferred reports whether pausing left any page or link check for a resume.
+func (r *crawlResult) hasDeferred() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.frontier) > 0 || len(r.pendingLinks) > 0
+}
+
+// fail records the failure of the start page, which fails the whole crawl.
+func (r *crawlResult) fail(err *CrawlError) {
+	r.mu.Lock()
+	r.failure = err
+	r.mu.Unlock()
+	atomic.StoreInt32(&r.crawlFailed, 1)
+}
+
+// startPage registers a page for the given request.
+func (r *crawlResult) startPage(req *colly.Request, statusCode int) *pageResult {
+	page := &pageResult{
+		url:        req.URL.String(),
+		depth:      pageDepth(req),
+		start:      isStartPage(req),
+		statusCode: statusCode,
+		headings:   make(map[string]int),
+	}
+	r.mu.Lock()
+	r.pages[req.ID] = page
+	r.mu.Unlock()
+	return page
+}
+
+// page returns the in-progress page for the request with the given ID.
+func (r *crawlResult) page(requestID uint32) *pageResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pages[requestID]
+}
+
+// skipPage records a page that was not fetched and does not count towards the totals.
+func (r *crawlResult) skipPage(url string, depth int, reason string) *pageResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if reason == models.SkipReasonRobots {
+		r.robotsSkipped++
+	}
+	return &pageResult{url: url, depth: depth, skipReason: reason}
+}
+
+// finishPage removes the page from the in-progress set and folds it into the totals.
+func (r *crawlResult) finishPage(requestID uint32) *pageResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	page, ok := r.pages[requestID]
+	if !ok {
+		return nil
+	}
+	delete(r.pages, requestID)
+
+	r.pagesCrawled++
+	if page.start {
+		r.pageTitle = page.title
+		r.htmlVersion = page.htmlVersion
+	}
+	for k, v := range page.headings {
+		r.headings[k] += v
+	}
+	r.internalLinks += page.internalLinks
+	r.externalLinks += page.externalLinks
+	r.hasLoginForm = r.hasLoginForm || page.hasLoginForm
+	return page
+}
+
+// addLinked records an internal URL that is reachable through links.
+func (r *crawlResult) addLinked(u *url.URL) {
+	key := r.normalizer.String(u)
+	r.mu.Lock()
+	if _, ok := r.linked[key]; !ok {
+		r.linked[key] = u.String()
+	}
+	r.mu.Unlock()
+}
+
+// sitemapIssues compares the sitemap with the internal links found during the crawl.
+// Linked URLs are only reported as unlisted when the site has a sitemap.
+func (r *crawlResult) sitemapIssues(websiteID, runID uint, sitemapURLs []string) []models.SitemapIssue {
+	if len(sitemapURLs) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var issues []models.SitemapIssue
+	listed := make(map[string]bool, len(sitemapURLs))
+	for _, loc := range sitemapURLs {
+		locURL, err := url.Parse(loc)
+		if err != nil {
+			continue
+		}
+		key := r.normalizer.String(locURL)
+		if listed[key] {
+			continue
+		}
+		listed[key] = true
+		if _, ok := r.linked[key]; !ok {
+			issues = append(issues, models.SitemapIssue{WebsiteID: websiteID, CrawlRunID: runID, URL: loc, Kind: models.SitemapOrphan})
+		}
+	}
+	for key, link := range r.linked {
+		if !listed[key] {
+			issues = append(issues, models.SitemapIssue{WebsiteID: websiteID, CrawlRunID: runID, URL: link, Kind: models.SitemapUnlisted})
+		}
+	}
+	return issues
+}
+
+// isStartPage reports whether req fetches the website URL itself, rather than a
+// linked page or a page seeded from the sitemap.
+func isStartPage(req *colly.Request) bool {
+	return pageDepth(req) == 1 && !isSitemapSeed(req)
+}
+
+func isSitemapSeed(req *colly.Request) bool {
+	return req.Ctx.GetAny(sitemapSeedKey) != nil
+}
+
+// pageDepth returns the depth of req within the crawl. Requests resumed from a checkpoint
+// restart at colly depth 1, so their context carries the depth they had before the pause.
+func pageDepth(req *colly.Request) int {
+	if offset, ok := req.Ctx.GetAny(depthOffsetKey).(int); ok {
+		return req.Depth + offset
+	}
+	return req.Depth
+}
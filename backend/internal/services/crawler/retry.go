@@ -0,0 +1,149 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	"web-crawler/backend/models"
+)
+
+// CrawlError is returned by ProcessURL when the crawl failed, with the classified cause.
+type CrawlError struct {
+	Code models.FailureCode
+	Err  error
+}
+
+func (e *CrawlError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CrawlError) Unwrap() error {
+	return e.Err
+}
+
+// FailureCode returns the classified cause of a failed crawl.
+func FailureCode(err error) models.FailureCode {
+	var crawlErr *CrawlError
+	if errors.As(err, &crawlErr) {
+		return crawlErr.Code
+	}
+	return models.FailureInternal
+}
+
+// classifyFailure determines why a request failed and whether it is worth retrying.
+func classifyFailure(statusCode int, err error) (code models.FailureCode, retryable bool) {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return models.FailureRateLimited, true
+	case statusCode >= 500:
+		return models.FailureServerError, statusCode != http.StatusNotImplemented
+	case statusCode >= 400:
+		return models.FailureClientError, false
+	case statusCode >= 300:
+		return models.FailureUnexpectedStatus, false
+	}
+
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return models.FailureUnexpectedStatus, false
+	case errors.As(err, &dnsErr):
+		// A missing domain will not appear on retry, a failing resolver may recover
+		return models.FailureDNS, !dnsErr.IsNotFound
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return models.FailureTimeout, true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return models.FailureConnectionReset, true
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return models.FailureConnectionRefused, true
+	default:
+		return models.FailureNetwork, false
+	}
+}
+
+// retryPolicy retries failed page requests with exponential backoff and jitter.
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+
+	mu       sync.Mutex
+	attempts map[string]int
+}
+
+func newRetryPolicy(maxRetries int, baseDelay, maxDelay time.Duration) *retryPolicy {
+	return &retryPolicy{
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+		maxDelay:   maxDelay,
+		attempts:   make(map[string]int),
+	}
+}
+
+// Retrying reports whether a request for url is a retry of an earlier failed attempt.
+func (rp *retryPolicy) Retrying(url string) bool {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return rp.attempts[url] > 0
+}
+
+// Next records a failed attempt for url and returns how long to wait before retrying it.
+// It returns false when url has run out of retries or the server asks to wait longer than
+// the maximum delay.
+func (rp *retryPolicy) Next(url string, headers *http.Header) (time.Duration, bool) {
+	rp.mu.Lock()
+	attempt := rp.attempts[url]
+	if attempt >= rp.maxRetries {
+		rp.mu.Unlock()
+		return 0, false
+	}
+	rp.attempts[url] = attempt + 1
+	rp.mu.Unlock()
+
+	if headers != nil {
+		if wait, ok := parseRetryAfter(headers.Get("Retry-After")); ok {
+			return wait, wait <= rp.maxDelay
+		}
+	}
+
+	delay := rp.baseDelay << attempt
+	if delay <= 0 || delay > rp.maxDelay {
+		delay = rp.maxDelay
+	}
+	// Equal jitter: wait at least half the backoff, so retries from many pages spread out
+	half := delay / 2
+	if half > 0 {
+		delay = half + rand.N(half)
+	}
+	return delay, true
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func failureMessage(url string, statusCode int, err error) error {
+	if statusCode > 0 {
+		return fmt.Errorf("fetching %s: status code %d: %w", url, statusCode, err)
+	}
+	return fmt.Errorf("fetching %s: %w", url, err)
+}
@@ -0,0 +1,127 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"web-crawler/backend/models"
+)
+
+// timeoutError is a network error reporting a timeout, like those of net/http clients.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyFailure(t *testing.T) {
+	opError := func(err error) error {
+		return &net.OpError{Op: "read", Net: "tcp", Err: &os.SyscallError{Syscall: "read", Err: err}}
+	}
+
+	tests := []struct {
+		name          string
+		statusCode    int
+		err           error
+		wantCode      models.FailureCode
+		wantRetryable bool
+	}{
+		{"rate limited", http.StatusTooManyRequests, nil, models.FailureRateLimited, true},
+		{"server error", http.StatusBadGateway, nil, models.FailureServerError, true},
+		{"not implemented", http.StatusNotImplemented, nil, models.FailureServerError, false},
+		{"client error", http.StatusNotFound, nil, models.FailureClientError, false},
+		{"redirect", http.StatusFound, nil, models.FailureUnexpectedStatus, false},
+		{"status takes precedence over error", http.StatusServiceUnavailable, io.EOF, models.FailureServerError, true},
+		{"no status and no error", 0, nil, models.FailureUnexpectedStatus, false},
+		{"unknown domain", 0, &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}, models.FailureDNS, false},
+		{"failing resolver", 0, &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}, models.FailureDNS, true},
+		{"deadline exceeded", 0, fmt.Errorf("get: %w", context.DeadlineExceeded), models.FailureTimeout, true},
+		{"network timeout", 0, &net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}, models.FailureTimeout, true},
+		{"connection reset", 0, opError(syscall.ECONNRESET), models.FailureConnectionReset, true},
+		{"unexpected EOF", 0, fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF), models.FailureConnectionReset, true},
+		{"connection refused", 0, opError(syscall.ECONNREFUSED), models.FailureConnectionRefused, true},
+		{"other error", 0, errors.New("tls: handshake failure"), models.FailureNetwork, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, retryable := classifyFailure(tt.statusCode, tt.err)
+			if code != tt.wantCode || retryable != tt.wantRetryable {
+				t.Errorf("classifyFailure(%d, %v) = %s, %t, want %s, %t", tt.statusCode, tt.err, code, retryable, tt.wantCode, tt.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"empty", "", 0, false},
+		{"seconds", "120", 2 * time.Minute, true},
+		{"zero seconds", "0", 0, true},
+		{"negative seconds", "-5", 0, false},
+		{"garbage", "soon", 0, false},
+		{"past date", "Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRetryAfter(%q) = %s, %t, want %s, %t", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	t.Run("future date", func(t *testing.T) {
+		value := time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat)
+		got, ok := parseRetryAfter(value)
+		// HTTP dates have a precision of one second
+		if !ok || got < 88*time.Second || got > 90*time.Second {
+			t.Errorf("parseRetryAfter(%q) = %s, %t, want about 90s", value, got, ok)
+		}
+	})
+}
+
+func TestRetryPolicyNext(t *testing.T) {
+	t.Run("backs off until retries run out", func(t *testing.T) {
+		rp := newRetryPolicy(3, time.Second, 30*time.Second)
+		for attempt := range 3 {
+			delay, ok := rp.Next("https://example.com/", nil)
+			backoff := time.Second << attempt
+			if !ok || delay < backoff/2 || delay >= backoff {
+				t.Errorf("attempt %d: Next = %s, %t, want between %s and %s", attempt, delay, ok, backoff/2, backoff)
+			}
+		}
+		if _, ok := rp.Next("https://example.com/", nil); ok {
+			t.Error("Next allowed a retry after the last one")
+		}
+		if !rp.Retrying("https://example.com/") || rp.Retrying("https://example.com/other") {
+			t.Error("Retrying must only report URLs that failed before")
+		}
+	})
+
+	t.Run("follows Retry-After up to the maximum delay", func(t *testing.T) {
+		rp := newRetryPolicy(3, time.Second, 30*time.Second)
+		headers := http.Header{}
+		headers.Set("Retry-After", "10")
+		if delay, ok := rp.Next("https://example.com/a", &headers); !ok || delay != 10*time.Second {
+			t.Errorf("Next = %s, %t, want 10s, true", delay, ok)
+		}
+		headers.Set("Retry-After", "60")
+		if _, ok := rp.Next("https://example.com/b", &headers); ok {
+			t.Error("Next allowed a retry the server asked to delay beyond the maximum")
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package crawler
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+// robotsRules fetches and caches the robots.txt rules of each host visited during a crawl.
+type robotsRules struct {
+	client    *http.Client
+	userAgent string
+
+	mu    sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+// robotsEntry holds the rules of one host, fetched once by the first request that needs them.
+type robotsEntry struct {
+	once sync.Once
+	data *robotstxt.RobotsData
+}
+
+func newRobotsRules(client *http.Client, userAgent string) *robotsRules {
+	return &robotsRules{
+		client:    client,
+		userAgent: userAgent,
+		hosts:     make(map[string]*robotsEntry),
+	}
+}
+
+// Allowed reports whether the configured user agent may fetch u.
+func (rr *robotsRules) Allowed(u *url.URL) bool {
+	return rr.data(u).TestAgent(u.RequestURI(), rr.userAgent)
+}
+
+// CrawlDelay returns the Crawl-delay the host of u asks of the configured user agent.
+func (rr *robotsRules) CrawlDelay(u *url.URL) time.Duration {
+	return rr.data(u).FindGroup(rr.userAgent).CrawlDelay
+}
+
+// Sitemaps returns the sitemap URLs advertised in the robots.txt of the host of u.
+func (rr *robotsRules) Sitemaps(u *url.URL) []string {
+	return rr.data(u).Sitemaps
+}
+
+// data returns the rules of the host of u. The lock only guards the map, so that a slow host
+// holds up the requests waiting for its own rules but not those to other hosts.
+func (rr *robotsRules) data(u *url.URL) *robotstxt.RobotsData {
+	rr.mu.Lock()
+	entry, ok := rr.hosts[u.Host]
+	if !ok {
+		entry = &robotsEntry{}
+		rr.hosts[u.Host] = entry
+	}
+	rr.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.data = rr.fetch(u)
+	})
+	return entry.data
+}
+
+func (rr *robotsRules) fetch(u *url.URL) *robotstxt.RobotsData {
+	robotsURL := u.Scheme + "://" + u.Host + "/robots.txt"
+
+	req, err := http.NewRequest(http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return allowAllRobots()
+	}
+	req.Header.Set("User-Agent", rr.userAgent)
+
+	resp, err := rr.client.Do(req)
+	if err != nil {
+		// An unreachable robots.txt is treated like a missing one
+		log.Printf("Failed to fetch %s: %v", robotsURL, err)
+		return allowAllRobots()
+	}
+	defer resp.Body.Close()
+
+	data, err := robotstxt.FromResponse(resp)
+	if err != nil || data == nil {
+		log.Printf("Failed to parse %s: %v", robotsURL, err)
+		return allowAllRobots()
+	}
+	return data
+}
+
+func allowAllRobots() *robotstxt.RobotsData {
+	data, _ := robotstxt.FromStatusAndBytes(http.StatusNotFound, nil)
+	return data
+}
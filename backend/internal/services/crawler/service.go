@@ -6,78 +6,195 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
-	"sync"
 	"sync/atomic"
 	"time"
+	"web-crawler/backend/internal/config"
+	"web-crawler/backend/internal/urlnorm"
 	"web-crawler/backend/models"
 
 	"github.com/gocolly/colly"
 	"gorm.io/gorm"
 )
 
-var ErrCrawlCancelled = errors.New("crawl cancelled by user")
+const (
+	// sitemapSeedKey marks requests seeded from the sitemap in their colly context.
+	sitemapSeedKey = "sitemapSeed"
+	// depthOffsetKey holds the depth lost by requests resumed from a checkpoint in their colly context.
+	depthOffsetKey = "depthOffset"
+)
+
+var (
+	ErrCrawlCancelled = errors.New("crawl cancelled by user")
+	ErrCrawlPaused    = errors.New("crawl paused by user")
+)
 
 type Service struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	Config config.Config
+	// Normalizer matches the different spellings of a page, so that it is fetched and listed once
+	Normalizer *urlnorm.Normalizer
+
+	client *http.Client
 }
 
-func NewService(db *gorm.DB) *Service {
-	return &Service{DB: db}
+func NewService(db *gorm.DB, cfg config.Config, normalizer *urlnorm.Normalizer) *Service {
+	return &Service{
+		DB:         db,
+		Config:     cfg,
+		Normalizer: normalizer,
+		client:     newHTTPClient(cfg.LinkCheckTimeout),
+	}
 }
 
-func (s *Service) ProcessURL(website *models.Website, cancelChan <-chan struct{}) error {
+// ProcessURL crawls a website, saving its pages, links and sitemap issues under the given crawl run,
+// and updates the website with the resulting metrics. When the crawl is paused through stop, its
+// progress is saved and ErrCrawlPaused is returned; calling ProcessURL again with the same run resumes it.
+// report, when not nil, receives throttled progress snapshots while the crawl runs.
+func (s *Service) ProcessURL(website *models.Website, runID uint, stop *StopSignal, report func(Progress)) error {
+	startedAt := time.Now()
 	c := colly.NewCollector(
-		colly.UserAgent("web-crawler"),
+		colly.UserAgent(s.Config.UserAgent),
 		colly.Async(true),
+		// colly counts the start page as depth 1
+		colly.MaxDepth(website.MaxDepth+1),
 	)
 
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*",
-		Parallelism: 8,
-	})
-
 	var (
 		requestProcessed int32
 		requestCount     int32
 	)
 
+	maxPages := website.MaxPages
+	if maxPages < 1 {
+		maxPages = 1
+	}
+
 	baseURL, err := url.Parse(website.URL)
 	if err != nil {
 		log.Printf("Failed to parse base URL %s: %v", website.URL, err)
 		website.Status = models.Failed
-		if dbErr := s.DB.Save(website).Error; dbErr != nil {
+		if dbErr := s.SaveResult(website); dbErr != nil {
 			log.Printf("Failed to save failed status for website %d: %v", website.ID, dbErr)
 		}
-		return err
+		return &CrawlError{Code: models.FailureInvalidURL, Err: err}
+	}
+
+	robots := newRobotsRules(s.client, s.Config.UserAgent)
+	if !website.IgnoreRobots {
+		if delay := robots.CrawlDelay(baseURL); delay > 0 {
+			// Rules are matched in order, so the site's Crawl-delay takes precedence over the default below
+			c.Limit(&colly.LimitRule{
+				DomainGlob:  baseURL.Host,
+				Delay:       delay,
+				Parallelism: 1,
+			})
+		}
+	}
+
+	c.Limit(&colly.LimitRule{
+		DomainGlob:  "*",
+		Parallelism: s.Config.CrawlParallelism,
+	})
+
+	result := newCrawlResult(s.Normalizer)
+
+	// Pages fetched before a pause are not fetched again
+	visited := make(map[string]bool)
+	state, err := s.loadCheckpoint(runID)
+	if err != nil {
+		log.Printf("Failed to load checkpoint of crawl run %d: %v", runID, err)
+		return &CrawlError{Code: models.FailureInternal, Err: err}
+	}
+	if state != nil {
+		result.restore(state)
+		requestCount = int32(state.PagesRequested)
+		requestProcessed = int32(state.PagesFetched)
+		// Checkpoints saved before URLs were normalized hold the URLs as fetched
+		for _, u := range state.Visited {
+			if pageURL, err := url.Parse(u); err == nil {
+				visited[s.Normalizer.String(pageURL)] = true
+			}
+		}
 	}
 
-	result := &crawlResult{
-		headings: make(map[string]int32),
+	retries := newRetryPolicy(s.Config.CrawlMaxRetries, s.Config.CrawlRetryBaseDelay, s.Config.CrawlRetryMaxDelay)
+	checker := newLinkChecker(s.client, s.Config.UserAgent, s.Config.LinkCheckConcurrency, s.Config.LinkCheckPerHost)
+
+	checkLink := func(record models.Link) {
+		checker.Check(record.TargetURL, func(status linkStatus) {
+			if status.aborted {
+				result.deferLink(record)
+				return
+			}
+			atomic.AddInt32(&result.linksChecked, 1)
+			status.applyTo(&record)
+			if record.Broken {
+				atomic.AddInt32(&result.brokenLinks, 1)
+			}
+			s.saveLink(&record)
+		})
 	}
 
 	c.OnRequest(func(r *colly.Request) {
+		pageURL := r.URL.String()
+		key := s.Normalizer.String(r.URL)
+		depth := pageDepth(r)
+		// colly's MaxDepth does not know the depth of requests resumed from a checkpoint
+		if depth > website.MaxDepth+1 || visited[key] {
+			r.Abort()
+			return
+		}
+
+		// Another spelling of a page already requested, such as with tracking parameters, is not fetched again
+		retrying := retries.Retrying(pageURL)
+		if !retrying && !result.markRequested(key) {
+			r.Abort()
+			return
+		}
+
 		select {
-		case <-cancelChan:
-			log.Printf("Cancellation requested for %s. Aborting request.", r.URL.String())
+		case <-stop.Done():
 			r.Abort()
+			if stop.Paused() {
+				result.deferRequest(pageURL, depth, isSitemapSeed(r))
+				return
+			}
+			log.Printf("Cancellation requested for %s. Aborting request.", pageURL)
 			atomic.StoreInt32(&result.crawlCancelled, 1)
+			return
 		default:
-			atomic.AddInt32(&requestCount, 1)
+		}
+
+		if !website.IgnoreRobots && !robots.Allowed(r.URL) {
+			log.Printf("Skipping %s, disallowed by robots.txt", pageURL)
+			r.Abort()
+			s.savePage(website.ID, runID, result.skipPage(pageURL, depth, models.SkipReasonRobots))
+			return
+		}
+
+		// Enforce the page budget, retries of a failed page do not count again
+		if retrying {
+			return
+		}
+		if int(atomic.AddInt32(&requestCount, 1)) > maxPages {
+			r.Abort()
+			return
 		}
 	})
 
 	c.OnResponse(func(r *colly.Response) {
 		atomic.AddInt32(&requestProcessed, 1)
-		if r.StatusCode >= 400 {
-			log.Printf("Crawl failed for %s with status code: %d", r.Request.URL, r.StatusCode)
-			atomic.StoreInt32(&result.crawlFailed, 1)
-			return
-		}
-		result.htmlVersion = detectHTMLVersion(r.Body)
+		page := result.startPage(r.Request, r.StatusCode)
+		page.htmlVersion = detectHTMLVersion(r.Body)
 	})
 
-	// links extraction and categorization
+	// links extraction, categorization and internal link following
 	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
+		page := result.page(e.Request.ID)
+		if page == nil {
+			return
+		}
+
 		link := e.Attr("href")
 		absoluteURL := e.Request.AbsoluteURL(link)
 
@@ -85,115 +202,344 @@ func (s *Service) ProcessURL(website *models.Website, cancelChan <-chan struct{}
 			return
 		}
 
+		record := models.Link{
+			WebsiteID:  website.ID,
+			CrawlRunID: runID,
+			SourceURL:  e.Request.URL.String(),
+			TargetURL:  absoluteURL,
+			AnchorText: strings.TrimSpace(e.Text),
+		}
+
 		linkURL, err := url.Parse(absoluteURL)
 		if err != nil {
 			atomic.AddInt32(&result.brokenLinks, 1)
+			record.Broken = true
+			record.Error = err.Error()
+			s.saveLink(&record)
 			return
 		}
 
-		if linkURL.Hostname() == baseURL.Hostname() {
-			atomic.AddInt32(&result.internalLinks, 1)
+		record.IsInternal = urlnorm.Hostname(linkURL) == urlnorm.Hostname(baseURL)
+		if record.IsInternal {
+			page.internalLinks++
+			if linkURL.Scheme == "http" || linkURL.Scheme == "https" {
+				result.addLinked(linkURL)
+				// Errors are expected here for already visited URLs or once the max depth is reached
+				e.Request.Visit(absoluteURL)
+			}
 		} else {
-			atomic.AddInt32(&result.externalLinks, 1)
+			page.externalLinks++
 		}
 
-		go checkLink(absoluteURL, &result.brokenLinks)
+		checkLink(record)
 	})
 
 	// Extract page title
 	c.OnHTML("title", func(e *colly.HTMLElement) {
-		result.mu.Lock()
-		result.pageTitle = e.Text
-		result.mu.Unlock()
+		if page := result.page(e.Request.ID); page != nil {
+			page.title = e.Text
+		}
 	})
 
 	// Count headings
 	c.OnHTML("h1, h2, h3, h4, h5, h6", func(e *colly.HTMLElement) {
-		result.mu.Lock()
-		result.headings[e.Name]++
-		result.mu.Unlock()
+		if page := result.page(e.Request.ID); page != nil {
+			page.headings[e.Name]++
+		}
 	})
 
 	// Check for a login form
 	c.OnHTML("form", func(e *colly.HTMLElement) {
 		// Check for password field, a strong indicator of a login form
 		if e.ChildAttr("input[type='password']", "name") != "" {
-			atomic.StoreInt32(&result.hasLoginForm, 1)
+			if page := result.page(e.Request.ID); page != nil {
+				page.hasLoginForm = true
+			}
 		}
 	})
 
 	// Handle errors during crawling
 	c.OnError(func(r *colly.Response, err error) {
+		page := result.page(r.Request.ID)
+		code, retryable := classifyFailure(r.StatusCode, err)
+		if page == nil && retryable {
+			if delay, ok := retries.Next(r.Request.URL.String(), r.Headers); ok {
+				log.Printf("Retrying %s in %s (status code: %d): %v", r.Request.URL, delay, r.StatusCode, err)
+				select {
+				case <-stop.Done():
+					if stop.Paused() {
+						// The page is fetched again on resume, so it gives its share of the budget back
+						atomic.AddInt32(&requestCount, -1)
+						result.deferRequest(r.Request.URL.String(), pageDepth(r.Request), isSitemapSeed(r.Request))
+					} else {
+						atomic.StoreInt32(&result.crawlCancelled, 1)
+					}
+					return
+				case <-time.After(delay):
+				}
+				if retryErr := r.Request.Retry(); retryErr == nil {
+					return
+				}
+			}
+		}
+
 		log.Printf("Crawl failed for %s (status code: %d): %v", r.Request.URL, r.StatusCode, err)
-		atomic.StoreInt32(&result.crawlFailed, 1)
 		atomic.AddInt32(&requestProcessed, 1)
+
+		// Only a failure of the start page fails the whole crawl
+		if isStartPage(r.Request) {
+			result.fail(&CrawlError{Code: code, Err: failureMessage(r.Request.URL.String(), r.StatusCode, err)})
+		}
+
+		if page != nil {
+			// The response was received but could not be parsed, OnScraped saves the page
+			page.err = err.Error()
+			return
+		}
+		page = result.startPage(r.Request, r.StatusCode)
+		page.err = err.Error()
+		s.savePage(website.ID, runID, result.finishPage(r.Request.ID))
 	})
 
-	// Finalize and save results after the crawl is complete
+	// Save each page once all of its callbacks have run
 	c.OnScraped(func(r *colly.Response) {
-		log.Printf("Crawl completed for %s", r.Request.URL)
+		log.Printf("Page crawled for %s", r.Request.URL)
+		s.savePage(website.ID, runID, result.finishPage(r.Request.ID))
+	})
 
-		if atomic.LoadInt32(&result.crawlFailed) != 0 {
-			website.Status = models.Failed
-		} else if atomic.LoadInt32(&result.crawlCancelled) != 0 {
-			website.Status = models.Cancelled
-		} else {
-			website.Status = models.Completed
+	// Link checks still queued when the crawl is paused are left for the resume
+	crawlDone := make(chan struct{})
+	defer close(crawlDone)
+	go func() {
+		select {
+		case <-stop.Done():
+			if stop.Paused() {
+				checker.Stop()
+			}
+		case <-crawlDone:
 		}
+	}()
+
+	if report != nil {
+		snapshot := func() Progress {
+			fetched := int(atomic.LoadInt32(&requestProcessed))
+			requested := min(int(atomic.LoadInt32(&requestCount)), maxPages)
+			return Progress{
+				PagesFetched: fetched,
+				PagesQueued:  max(requested-fetched, 0),
+				MaxPages:     maxPages,
+				LinksChecked: int(atomic.LoadInt32(&result.linksChecked)),
+				BrokenLinks:  int(atomic.LoadInt32(&result.brokenLinks)),
+				ElapsedMs:    time.Since(startedAt).Milliseconds(),
+			}
+		}
+		progressDone := make(chan struct{})
+		reporterDone := make(chan struct{})
+		go func() {
+			reportProgress(s.Config.CrawlProgressInterval, snapshot, report, progressDone)
+			close(reporterDone)
+		}()
+		// The last snapshot is reported before ProcessURL returns and the final status is announced
+		defer func() {
+			close(progressDone)
+			<-reporterDone
+		}()
+	}
 
-		result.mu.RLock()
-		defer result.mu.RUnlock()
-
-		website.Title = result.pageTitle
-		website.HTMLVersion = result.htmlVersion
-		website.InternalLinks = int(atomic.LoadInt32(&result.internalLinks))
-		website.ExternalLinks = int(atomic.LoadInt32(&result.externalLinks))
-		website.BrokenLinks = int(atomic.LoadInt32(&result.brokenLinks))
-		website.HasLoginForm = atomic.LoadInt32(&result.hasLoginForm) == 1
+	var sitemapURLs []string
+	if state == nil {
+		sitemapURLs = s.discoverSitemap(baseURL, robots)
 
-		headingsCount := make(map[string]int)
-		for k, v := range result.headings {
-			headingsCount[k] = int(v)
+		if err := c.Visit(website.URL); err != nil {
+			checker.Wait()
+			log.Printf("Failed to start visit for URL %s: %v", website.URL, err)
+			website.Status = models.Failed
+			if dbErr := s.SaveResult(website); dbErr != nil {
+				log.Printf("Failed to save failed status for website %d: %v", website.ID, dbErr)
+			}
+			return &CrawlError{Code: models.FailureInvalidURL, Err: err}
 		}
-		website.HeadingsCount = headingsCount
+		result.addLinked(baseURL)
+
+		// Seed the frontier with the sitemap, within the page budget
+		for i, seed := range sitemapURLs {
+			if i >= maxPages {
+				break
+			}
+			ctx := colly.NewContext()
+			ctx.Put(sitemapSeedKey, true)
+			// Errors are expected here for URLs already visited from the start page
+			c.Request(http.MethodGet, seed, nil, ctx, nil)
+		}
+	} else {
+		log.Printf("Resuming crawl of %s (%d pages left)", website.URL, len(state.Frontier))
+		sitemapURLs = state.SitemapURLs
+		for _, link := range state.PendingLinks {
+			checkLink(link)
+		}
+		for _, entry := range state.Frontier {
+			ctx := colly.NewContext()
+			ctx.Put(depthOffsetKey, entry.Depth-1)
+			if entry.Seed {
+				ctx.Put(sitemapSeedKey, true)
+			}
+			c.Request(http.MethodGet, entry.URL, nil, ctx, nil)
+		}
+	}
 
-		if err := s.DB.Save(website).Error; err != nil {
-			log.Printf("Failed to save website data for %s: %v", website.URL, err)
+	// The crawl is only finished once every link check has settled
+	c.Wait()
+	checker.Wait()
+
+	// A pause that arrives after the last page was fetched leaves nothing to resume
+	if stop.Paused() && result.hasDeferred() {
+		checkpoint := result.checkpoint(visited, sitemapURLs, int(atomic.LoadInt32(&requestCount)), int(atomic.LoadInt32(&requestProcessed)))
+		if err := s.saveCheckpoint(website.ID, runID, checkpoint); err != nil {
+			log.Printf("Failed to save checkpoint of crawl run %d: %v", runID, err)
+			return &CrawlError{Code: models.FailureInternal, Err: err}
 		}
-	})
+		log.Printf("Crawl paused for %s (%d pages, %d left)", website.URL, result.pagesCrawled, len(checkpoint.Frontier))
+		return ErrCrawlPaused
+	}
+	log.Printf("Crawl completed for %s (%d pages)", website.URL, result.pagesCrawled)
 
-	if err := c.Visit(website.URL); err != nil {
-		log.Printf("Failed to start visit for URL %s: %v", website.URL, err)
+	// Finalize and save the site totals after the crawl is complete
+	if atomic.LoadInt32(&result.crawlFailed) != 0 {
 		website.Status = models.Failed
-		if dbErr := s.DB.Save(website).Error; dbErr != nil {
-			log.Printf("Failed to save failed status for website %d: %v", website.ID, dbErr)
+	} else if atomic.LoadInt32(&result.crawlCancelled) != 0 {
+		website.Status = models.Cancelled
+	} else {
+		website.Status = models.Completed
+	}
+
+	issues := result.sitemapIssues(website.ID, runID, sitemapURLs)
+	s.saveSitemapIssues(website.ID, issues)
+
+	metrics := models.CrawlMetrics{
+		Title:         result.pageTitle,
+		HTMLVersion:   result.htmlVersion,
+		InternalLinks: result.internalLinks,
+		ExternalLinks: result.externalLinks,
+		BrokenLinks:   int(atomic.LoadInt32(&result.brokenLinks)),
+		HasLoginForm:  result.hasLoginForm,
+		HeadingsCount: result.headings,
+		PagesCrawled:  result.pagesCrawled,
+		RobotsSkipped: result.robotsSkipped,
+		SitemapFound:  len(sitemapURLs) > 0,
+		SitemapURLs:   len(sitemapURLs),
+	}
+	for _, issue := range issues {
+		if issue.Kind == models.SitemapOrphan {
+			metrics.OrphanPages++
+		} else {
+			metrics.UnlistedPages++
 		}
-		return err
 	}
+	website.CrawlMetrics = metrics
 
-	c.Wait()
+	if err := s.SaveResult(website); err != nil {
+		log.Printf("Failed to save website data for %s: %v", website.URL, err)
+	}
+	if state != nil {
+		if err := s.DiscardCheckpoint(runID); err != nil {
+			log.Printf("Failed to delete checkpoint of crawl run %d: %v", runID, err)
+		}
+	}
 
 	if atomic.LoadInt32(&result.crawlCancelled) != 0 {
 		return ErrCrawlCancelled
 	}
 
 	if atomic.LoadInt32(&result.crawlFailed) != 0 {
-		return errors.New("crawling failed")
+		return result.failure
 	}
 	return nil
 }
 
-type crawlResult struct {
-	mu             sync.RWMutex
-	pageTitle      string
-	htmlVersion    string
-	headings       map[string]int32
-	internalLinks  int32
-	externalLinks  int32
-	brokenLinks    int32
-	hasLoginForm   int32
-	crawlFailed    int32
-	crawlCancelled int32
+// SaveResult stores the status and metrics of a crawl on its website. Only the crawl result
+// columns are written, so that settings, schedules and groups changed while the crawl ran are
+// kept, and a website moved to the trash meanwhile stays there.
+func (s *Service) SaveResult(website *models.Website) error {
+	metrics := website.CrawlMetrics
+	return s.DB.Model(&models.Website{}).Where("id = ? AND deleted_at IS NULL", website.ID).Updates(map[string]any{
+		"status":            website.Status,
+		"html_version":      metrics.HTMLVersion,
+		"title":             metrics.Title,
+		"headings_count":    metrics.HeadingsCount,
+		"internal_links":    metrics.InternalLinks,
+		"external_links":    metrics.ExternalLinks,
+		"broken_links":      metrics.BrokenLinks,
+		"has_login_form":    metrics.HasLoginForm,
+		"pages_crawled":     metrics.PagesCrawled,
+		"robots_skipped":    metrics.RobotsSkipped,
+		"sitemap_found":     metrics.SitemapFound,
+		"sitemap_urls":      metrics.SitemapURLs,
+		"orphan_pages":      metrics.OrphanPages,
+		"unlisted_pages":    metrics.UnlistedPages,
+		"crawl_finished_at": website.CrawlFinishedAt,
+		"failure_code":      website.FailureCode,
+		"failure_reason":    website.FailureReason,
+	}).Error
+}
+
+// discoverSitemap reads the sitemaps advertised in robots.txt, falling back to /sitemap.xml,
+// and returns the listed URLs that belong to the crawled site.
+func (s *Service) discoverSitemap(baseURL *url.URL, robots *robotsRules) []string {
+	candidates := robots.Sitemaps(baseURL)
+	if len(candidates) == 0 {
+		candidates = []string{baseURL.Scheme + "://" + baseURL.Host + "/sitemap.xml"}
+	}
+
+	reader := newSitemapReader(s.client, s.Config.UserAgent)
+	for _, candidate := range candidates {
+		if err := reader.Read(candidate); err != nil {
+			log.Printf("Failed to read sitemap for %s: %v", baseURL.Host, err)
+		}
+	}
+
+	var urls []string
+	for _, loc := range reader.URLs() {
+		locURL, err := url.Parse(loc)
+		if err != nil || locURL.Hostname() != baseURL.Hostname() {
+			continue
+		}
+		urls = append(urls, loc)
+	}
+	return urls
+}
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: http.DefaultTransport.(*http.Transport).Clone(),
+	}
+}
+
+// savePage persists the results of a single crawled page.
+func (s *Service) savePage(websiteID, runID uint, page *pageResult) {
+	if page == nil {
+		return
+	}
+	record := page.toModel(websiteID, runID)
+	if err := s.DB.Create(&record).Error; err != nil {
+		log.Printf("Failed to save page %s for website %d: %v", page.url, websiteID, err)
+	}
+}
+
+// saveLink persists a discovered link together with its check result.
+func (s *Service) saveLink(link *models.Link) {
+	if err := s.DB.Create(link).Error; err != nil {
+		log.Printf("Failed to save link %s for website %d: %v", link.TargetURL, link.WebsiteID, err)
+	}
+}
+
+func (s *Service) saveSitemapIssues(websiteID uint, issues []models.SitemapIssue) {
+	if len(issues) == 0 {
+		return
+	}
+	if err := s.DB.CreateInBatches(issues, 500).Error; err != nil {
+		log.Printf("Failed to save sitemap issues for website %d: %v", websiteID, err)
+	}
 }
 
 func detectHTMLVersion(body []byte) string {
@@ -209,19 +555,3 @@ func detectHTMLVersion(body []byte) string {
 	}
 	return "Unknown or older"
 }
-
-func checkLink(url string, brokenLinks *int32) {
-	client := http.Client{
-		Timeout: 10 * time.Second,
-	}
-	resp, err := client.Head(url)
-	if err != nil {
-		atomic.AddInt32(brokenLinks, 1)
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 && resp.StatusCode < 600 {
-		atomic.AddInt32(brokenLinks, 1)
-	}
-}
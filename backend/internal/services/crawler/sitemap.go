@@ -0,0 +1,141 @@
+package crawler
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// maxSitemapSize is the largest uncompressed sitemap accepted, as set by the sitemaps protocol
+	maxSitemapSize = 50 << 20
+	// maxSitemapFiles bounds the number of sitemap files read, following nested indexes
+	maxSitemapFiles = 50
+	// maxSitemapURLs bounds the number of page URLs collected from all sitemaps
+	maxSitemapURLs = 50000
+)
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// sitemapDocument matches both <urlset> and <sitemapindex> documents.
+type sitemapDocument struct {
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+// sitemapReader collects page URLs from sitemaps, following sitemap indexes.
+type sitemapReader struct {
+	client    *http.Client
+	userAgent string
+
+	seen  map[string]bool
+	files int
+	urls  []string
+}
+
+func newSitemapReader(client *http.Client, userAgent string) *sitemapReader {
+	return &sitemapReader{
+		client:    client,
+		userAgent: userAgent,
+		seen:      make(map[string]bool),
+	}
+}
+
+// Read collects the page URLs listed in the sitemap at sitemapURL and in any sitemap it indexes.
+func (sr *sitemapReader) Read(sitemapURL string) error {
+	if sr.seen[sitemapURL] || sr.files >= maxSitemapFiles || len(sr.urls) >= maxSitemapURLs {
+		return nil
+	}
+	sr.seen[sitemapURL] = true
+	sr.files++
+
+	doc, err := sr.fetch(sitemapURL)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range doc.URLs {
+		if len(sr.urls) >= maxSitemapURLs {
+			break
+		}
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			sr.urls = append(sr.urls, loc)
+		}
+	}
+
+	var lastErr error
+	for _, sm := range doc.Sitemaps {
+		if loc := strings.TrimSpace(sm.Loc); loc != "" {
+			if err := sr.Read(loc); err != nil {
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
+
+// URLs returns the page URLs collected so far.
+func (sr *sitemapReader) URLs() []string {
+	return sr.urls
+}
+
+func (sr *sitemapReader) fetch(sitemapURL string) (*sitemapDocument, error) {
+	req, err := http.NewRequest(http.MethodGet, sitemapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", sr.userAgent)
+
+	resp, err := sr.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching sitemap %s: unexpected status code %d", sitemapURL, resp.StatusCode)
+	}
+
+	body, err := sitemapBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading sitemap %s: %w", sitemapURL, err)
+	}
+
+	var doc sitemapDocument
+	if err := xml.NewDecoder(body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing sitemap %s: %w", sitemapURL, err)
+	}
+	return &doc, nil
+}
+
+// sitemapBody returns a reader over the uncompressed sitemap, transparently handling gzipped files.
+func sitemapBody(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return io.LimitReader(gz, maxSitemapSize), nil
+	}
+	return io.LimitReader(br, maxSitemapSize), nil
+}
+
+// ReadSitemap returns the page URLs listed in the sitemap at sitemapURL and in any sitemap it indexes.
+// It fails only when no URL could be read.
+func (s *Service) ReadSitemap(sitemapURL string) ([]string, error) {
+	reader := newSitemapReader(s.client, s.Config.UserAgent)
+	err := reader.Read(sitemapURL)
+	if err != nil && len(reader.URLs()) == 0 {
+		return nil, err
+	}
+	return reader.URLs(), nil
+}
@@ -0,0 +1,47 @@
+package crawler
+
+import "sync"
+
+// StopSignal stops a running crawl, either cancelling it or pausing it so that it can be resumed later.
+// Only the first request to stop takes effect.
+type StopSignal struct {
+	done   chan struct{}
+	once   sync.Once
+	paused bool
+}
+
+func NewStopSignal() *StopSignal {
+	return &StopSignal{done: make(chan struct{})}
+}
+
+// Cancel stops the crawl for good.
+func (s *StopSignal) Cancel() {
+	s.stop(false)
+}
+
+// Pause stops the crawl, saving its progress for a later resume.
+func (s *StopSignal) Pause() {
+	s.stop(true)
+}
+
+func (s *StopSignal) stop(pause bool) {
+	s.once.Do(func() {
+		s.paused = pause
+		close(s.done)
+	})
+}
+
+// Done is closed once the crawl was asked to stop.
+func (s *StopSignal) Done() <-chan struct{} {
+	return s.done
+}
+
+// Paused reports whether the crawl was asked to pause rather than cancel.
+func (s *StopSignal) Paused() bool {
+	select {
+	case <-s.done:
+		return s.paused
+	default:
+		return false
+	}
+}
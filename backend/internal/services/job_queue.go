@@ -0,0 +1,279 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrScanInProgress = errors.New("scan already in progress")
+	ErrScanPaused     = errors.New("scan is paused, resume or cancel it first")
+)
+
+// JobQueue is a database backed queue of scan jobs, shared by every server instance.
+type JobQueue struct {
+	DB            *gorm.DB
+	LeaseDuration time.Duration
+
+	owner     string
+	enqueueMu sync.Mutex
+}
+
+func NewJobQueue(db *gorm.DB, leaseDuration time.Duration) *JobQueue {
+	hostname, _ := os.Hostname()
+	return &JobQueue{
+		DB:            db,
+		LeaseDuration: leaseDuration,
+		owner:         fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano()),
+	}
+}
+
+// Enqueue adds a pending scan job for a website, unless one is already pending, running or paused.
+func (q *JobQueue) Enqueue(websiteID uint) (*models.CrawlJob, error) {
+	q.enqueueMu.Lock()
+	defer q.enqueueMu.Unlock()
+
+	active, err := q.Active(websiteID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, ErrScanInProgress
+	}
+	paused, err := q.Paused(websiteID)
+	if err != nil {
+		return nil, err
+	}
+	if paused != nil {
+		return nil, ErrScanPaused
+	}
+
+	job := models.CrawlJob{WebsiteID: websiteID, Status: models.JobPending}
+	if err := q.DB.Create(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Active returns the pending or running job of a website, or nil when there is none.
+func (q *JobQueue) Active(websiteID uint) (*models.CrawlJob, error) {
+	var jobs []models.CrawlJob
+	err := q.DB.Where("website_id = ? AND status IN ?", websiteID, []models.JobStatus{models.JobPending, models.JobRunning}).
+		Order("id asc").Limit(1).Find(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
+
+// Paused returns the paused job of a website, or nil when there is none.
+func (q *JobQueue) Paused(websiteID uint) (*models.CrawlJob, error) {
+	var jobs []models.CrawlJob
+	err := q.DB.Where("website_id = ? AND status = ?", websiteID, models.JobPaused).
+		Order("id desc").Limit(1).Find(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
+
+// Status returns the current status of a job.
+func (q *JobQueue) Status(jobID uint) (models.JobStatus, error) {
+	var job models.CrawlJob
+	if err := q.DB.Select("status").First(&job, jobID).Error; err != nil {
+		return "", err
+	}
+	return job.Status, nil
+}
+
+// Claim leases the oldest pending job to this instance. It returns nil when no job is pending.
+func (q *JobQueue) Claim() (*models.CrawlJob, error) {
+	var claimed *models.CrawlJob
+	err := q.DB.Transaction(func(tx *gorm.DB) error {
+		var jobs []models.CrawlJob
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.JobPending).Order("id asc").Limit(1).Find(&jobs).Error
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		job := jobs[0]
+		expiresAt := time.Now().Add(q.LeaseDuration)
+		job.Status = models.JobRunning
+		job.Attempts++
+		job.LeaseOwner = q.owner
+		job.LeaseExpiresAt = &expiresAt
+		if err := tx.Save(&job).Error; err != nil {
+			return err
+		}
+		claimed = &job
+		return nil
+	})
+	return claimed, err
+}
+
+// KeepAlive renews the lease of a running job until the returned stop function is called.
+// onLost is called when the lease can no longer be renewed, because the job was cancelled,
+// paused or handed to another worker.
+func (q *JobQueue) KeepAlive(job *models.CrawlJob, onLost func()) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(q.LeaseDuration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				result := q.DB.Model(&models.CrawlJob{}).
+					Where("id = ? AND lease_owner = ? AND status = ?", job.ID, q.owner, models.JobRunning).
+					Update("lease_expires_at", time.Now().Add(q.LeaseDuration))
+				if result.Error != nil {
+					log.Printf("Failed to renew lease of job %d: %v", job.ID, result.Error)
+				} else if result.RowsAffected == 0 {
+					log.Printf("Lost lease of job %d", job.ID)
+					onLost()
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// Finish releases a running job with its final status. Jobs that were cancelled in the meantime keep their
+// status, jobs asked to pause that ran to completion anyway are finished.
+func (q *JobQueue) Finish(job *models.CrawlJob, status models.JobStatus) error {
+	return q.DB.Model(&models.CrawlJob{}).
+		Where("id = ? AND status IN ?", job.ID, []models.JobStatus{models.JobRunning, models.JobPaused}).
+		Updates(map[string]any{
+			"status":           status,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+		}).Error
+}
+
+// Pause releases a running job whose crawl run was paused, so that resuming the job continues that run.
+// It reports false when the job was cancelled in the meantime.
+func (q *JobQueue) Pause(job *models.CrawlJob, runID uint) (bool, error) {
+	result := q.DB.Model(&models.CrawlJob{}).
+		Where("id = ? AND status IN ?", job.ID, []models.JobStatus{models.JobRunning, models.JobPaused}).
+		Updates(map[string]any{
+			"status":           models.JobPaused,
+			"crawl_run_id":     runID,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RequestPause asks the worker running a job on another instance to pause it.
+// The worker notices once it fails to renew its lease.
+func (q *JobQueue) RequestPause(job *models.CrawlJob) error {
+	return q.DB.Model(&models.CrawlJob{}).
+		Where("id = ? AND status = ?", job.ID, models.JobRunning).
+		Update("status", models.JobPaused).Error
+}
+
+// PausePending holds back the pending job of a website until it is resumed. It reports whether a job was paused.
+func (q *JobQueue) PausePending(websiteID uint) (bool, error) {
+	result := q.DB.Model(&models.CrawlJob{}).
+		Where("website_id = ? AND status = ?", websiteID, models.JobPending).
+		Update("status", models.JobPaused)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Resume puts the paused job of a website back in the queue. It reports whether a job was resumed.
+func (q *JobQueue) Resume(websiteID uint) (bool, error) {
+	q.enqueueMu.Lock()
+	defer q.enqueueMu.Unlock()
+
+	active, err := q.Active(websiteID)
+	if err != nil {
+		return false, err
+	}
+	if active != nil {
+		return false, ErrScanInProgress
+	}
+	paused, err := q.Paused(websiteID)
+	if err != nil || paused == nil {
+		return false, err
+	}
+
+	result := q.DB.Model(&models.CrawlJob{}).
+		Where("id = ? AND status = ?", paused.ID, models.JobPaused).
+		Update("status", models.JobPending)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CancelPending cancels the pending job of a website. It returns the cancelled job, or nil when there was none.
+func (q *JobQueue) CancelPending(websiteID uint) (*models.CrawlJob, error) {
+	return q.cancel(websiteID, models.JobPending)
+}
+
+// CancelPaused cancels the paused job of a website. It returns the cancelled job, or nil when there was none.
+func (q *JobQueue) CancelPaused(websiteID uint) (*models.CrawlJob, error) {
+	return q.cancel(websiteID, models.JobPaused)
+}
+
+func (q *JobQueue) cancel(websiteID uint, status models.JobStatus) (*models.CrawlJob, error) {
+	var jobs []models.CrawlJob
+	if err := q.DB.Where("website_id = ? AND status = ?", websiteID, status).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	for i := range jobs {
+		result := q.DB.Model(&models.CrawlJob{}).
+			Where("id = ? AND status = ?", jobs[i].ID, status).
+			Update("status", models.JobCancelled)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return &jobs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CancelWebsites cancels the pending, running and paused jobs of websites within tx, and returns the
+// jobs as they were before. Workers running one of them stop once they fail to renew their lease.
+func (q *JobQueue) CancelWebsites(tx *gorm.DB, websiteIDs []int) ([]models.CrawlJob, error) {
+	statuses := []models.JobStatus{models.JobPending, models.JobRunning, models.JobPaused}
+	var jobs []models.CrawlJob
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("website_id IN ? AND status IN ?", websiteIDs, statuses).Find(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+	err = tx.Model(&models.CrawlJob{}).Where("id IN ?", ids).Updates(map[string]any{
+		"status":           models.JobCancelled,
+		"lease_owner":      "",
+		"lease_expires_at": nil,
+	}).Error
+	return jobs, err
+}
+
+// RequeueExpired puts running jobs whose lease expired, because their worker died, back in the queue.
+func (q *JobQueue) RequeueExpired() (int64, error) {
+	result := q.DB.Model(&models.CrawlJob{}).
+		Where("status = ? AND lease_expires_at < ?", models.JobRunning, time.Now()).
+		Updates(map[string]any{
+			"status":           models.JobPending,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+)
+
+type LinkService struct {
+	DB *gorm.DB
+}
+
+func NewLinkService(db *gorm.DB) *LinkService {
+	return &LinkService{DB: db}
+}
+
+type GetLinksParams struct {
+	Page       int
+	Limit      int
+	RunID      string
+	Search     string
+	Type       string
+	Broken     string
+	StatusCode string
+	SourceURL  string
+}
+
+// GetLinks returns the links discovered while crawling a website.
+func (s *LinkService) GetLinks(websiteID int, params GetLinksParams) ([]models.Link, int64, error) {
+	if err := s.DB.Select("id").First(&models.Website{}, websiteID).Error; err != nil {
+		return nil, 0, err
+	}
+
+	runID, err := resolveRunID(s.DB, websiteID, params.RunID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := s.DB.Model(&models.Link{}).Where("website_id = ? AND crawl_run_id = ?", websiteID, runID)
+	query = s.buildFilterQuery(params, query)
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	var links []models.Link
+	if result := query.Order("id asc").Offset(offset).Limit(params.Limit).Find(&links); result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return links, totalItems, nil
+}
+
+func (s *LinkService) buildFilterQuery(params GetLinksParams, query *gorm.DB) *gorm.DB {
+	if params.Search != "" {
+		likeQuery := "%" + params.Search + "%"
+		query = query.Where("target_url LIKE ? OR anchor_text LIKE ?", likeQuery, likeQuery)
+	}
+	if params.Type == "internal" || params.Type == "external" {
+		query = query.Where("is_internal = ?", params.Type == "internal")
+	}
+	if params.Broken != "" && params.Broken != "all" {
+		query = query.Where("broken = ?", params.Broken == "yes")
+	}
+	if params.StatusCode != "" {
+		query = query.Where("status_code = ?", params.StatusCode)
+	}
+	if params.SourceURL != "" {
+		query = query.Where("source_url = ?", params.SourceURL)
+	}
+	return query
+}
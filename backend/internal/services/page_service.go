@@ -0,0 +1,91 @@
+package services
+
+import (
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+)
+
+type PageService struct {
+	DB *gorm.DB
+}
+
+func NewPageService(db *gorm.DB) *PageService {
+	return &PageService{DB: db}
+}
+
+type GetPagesParams struct {
+	Page    int
+	Limit   int
+	RunID   string
+	Skipped string
+}
+
+// GetPages returns the pages crawled for a website, in crawl order.
+func (s *PageService) GetPages(websiteID int, params GetPagesParams) ([]models.Page, int64, error) {
+	if err := s.DB.Select("id").First(&models.Website{}, websiteID).Error; err != nil {
+		return nil, 0, err
+	}
+
+	runID, err := resolveRunID(s.DB, websiteID, params.RunID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := s.DB.Model(&models.Page{}).Where("website_id = ? AND crawl_run_id = ?", websiteID, runID)
+	if params.Skipped == "yes" {
+		query = query.Where("skip_reason <> ''")
+	} else if params.Skipped == "no" {
+		query = query.Where("skip_reason = ''")
+	}
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	var pages []models.Page
+	if result := query.Order("id asc").Offset(offset).Limit(params.Limit).Find(&pages); result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return pages, totalItems, nil
+}
+
+type GetSitemapIssuesParams struct {
+	Page  int
+	Limit int
+	RunID string
+	Kind  string
+}
+
+// GetSitemapIssues returns the mismatches between a website's sitemap and its internal links.
+func (s *PageService) GetSitemapIssues(websiteID int, params GetSitemapIssuesParams) ([]models.SitemapIssue, int64, error) {
+	if err := s.DB.Select("id").First(&models.Website{}, websiteID).Error; err != nil {
+		return nil, 0, err
+	}
+
+	runID, err := resolveRunID(s.DB, websiteID, params.RunID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := s.DB.Model(&models.SitemapIssue{}).Where("website_id = ? AND crawl_run_id = ?", websiteID, runID)
+	if params.Kind == models.SitemapOrphan || params.Kind == models.SitemapUnlisted {
+		query = query.Where("kind = ?", params.Kind)
+	}
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	var issues []models.SitemapIssue
+	if result := query.Order("id asc").Offset(offset).Limit(params.Limit).Find(&issues); result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return issues, totalItems, nil
+}
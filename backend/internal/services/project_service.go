@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidProject = errors.New("invalid project")
+	ErrProjectExists  = errors.New("a project with this name already exists")
+)
+
+type ProjectService struct {
+	DB *gorm.DB
+}
+
+func NewProjectService(db *gorm.DB) *ProjectService {
+	return &ProjectService{DB: db}
+}
+
+type ProjectInput struct {
+	Name        string
+	Description string
+}
+
+func (in ProjectInput) apply(project *models.Project) error {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidProject)
+	}
+	if len(name) > 191 {
+		return fmt.Errorf("%w: name is longer than 191 characters", ErrInvalidProject)
+	}
+	project.Name = name
+	project.Description = in.Description
+	return nil
+}
+
+func (s *ProjectService) GetProjects(page, limit int) ([]models.Project, int64, error) {
+	query := s.DB.Model(&models.Project{})
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	var projects []models.Project
+	if err := query.Order("name asc").Offset(offset).Limit(limit).Find(&projects).Error; err != nil {
+		return nil, 0, err
+	}
+	return projects, totalItems, nil
+}
+
+func (s *ProjectService) GetProject(id int) (*models.Project, error) {
+	var project models.Project
+	if err := s.DB.First(&project, id).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (s *ProjectService) CreateProject(input ProjectInput) (*models.Project, error) {
+	var project models.Project
+	if err := input.apply(&project); err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(project.Name, 0); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Create(&project).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (s *ProjectService) UpdateProject(id int, input ProjectInput) (*models.Project, error) {
+	project, err := s.GetProject(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := input.apply(project); err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(project.Name, project.ID); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Save(project).Error; err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (s *ProjectService) checkNameAvailable(name string, id uint) error {
+	var taken int64
+	if err := s.DB.Model(&models.Project{}).Where("name = ? AND id <> ?", name, id).Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrProjectExists
+	}
+	return nil
+}
+
+// DeleteProject deletes a project and ungroups its websites, which are kept. The project is
+// deleted for good rather than soft-deleted, so that its name can be used again.
+func (s *ProjectService) DeleteProject(id int) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Website{}).Where("project_id = ?", id).Update("project_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Delete(&models.Project{}, id)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
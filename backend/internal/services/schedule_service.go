@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"web-crawler/backend/internal/cron"
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+)
+
+const minScheduleInterval = time.Minute
+
+var (
+	ErrScheduleAlreadyExists = errors.New("schedule already exists")
+	ErrInvalidSchedule       = errors.New("invalid schedule")
+)
+
+type ScheduleService struct {
+	DB         *gorm.DB
+	URLService *URLService
+}
+
+func NewScheduleService(db *gorm.DB, urlService *URLService) *ScheduleService {
+	return &ScheduleService{DB: db, URLService: urlService}
+}
+
+// ScheduleInput describes when a website is scanned. Exactly one of Cron and Interval must be set.
+type ScheduleInput struct {
+	Cron     string
+	Interval string
+	Enabled  bool
+}
+
+func (in ScheduleInput) apply(schedule *models.Schedule) error {
+	if (in.Cron == "") == (in.Interval == "") {
+		return fmt.Errorf("%w: exactly one of cron and interval must be set", ErrInvalidSchedule)
+	}
+	if in.Cron != "" {
+		if _, err := cron.Parse(in.Cron); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+	} else {
+		interval, err := time.ParseDuration(in.Interval)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		if interval < minScheduleInterval {
+			return fmt.Errorf("%w: interval must be at least %s", ErrInvalidSchedule, minScheduleInterval)
+		}
+	}
+
+	schedule.Cron = in.Cron
+	schedule.Interval = in.Interval
+	schedule.Enabled = in.Enabled
+	return nil
+}
+
+// nextRun returns the first time after t at which the schedule starts a scan,
+// or nil when the schedule is disabled.
+func nextRun(schedule *models.Schedule, t time.Time) *time.Time {
+	if !schedule.Enabled {
+		return nil
+	}
+
+	var next time.Time
+	if schedule.Cron != "" {
+		expr, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			return nil
+		}
+		next = expr.Next(t)
+	} else {
+		interval, err := time.ParseDuration(schedule.Interval)
+		if err != nil {
+			return nil
+		}
+		next = t.Add(interval)
+	}
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+func (s *ScheduleService) GetSchedules(page, limit int) ([]models.Schedule, int64, error) {
+	query := s.DB.Model(&models.Schedule{})
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	var schedules []models.Schedule
+	if err := query.Order("id desc").Offset(offset).Limit(limit).Find(&schedules).Error; err != nil {
+		return nil, 0, err
+	}
+	return schedules, totalItems, nil
+}
+
+func (s *ScheduleService) GetSchedule(websiteID int) (*models.Schedule, error) {
+	var schedule models.Schedule
+	if err := s.DB.Where("website_id = ?", websiteID).First(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (s *ScheduleService) CreateSchedule(websiteID int, input ScheduleInput) (*models.Schedule, error) {
+	website, err := s.URLService.GetURLByID(websiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetSchedule(websiteID); err == nil {
+		return nil, ErrScheduleAlreadyExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	schedule := models.Schedule{WebsiteID: website.ID}
+	if err := input.apply(&schedule); err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// Schedules deleted before they were removed for good still hold the website's unique index
+		if err := tx.Unscoped().Where("website_id = ? AND deleted_at IS NOT NULL", website.ID).Delete(&models.Schedule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&schedule).Error; err != nil {
+			return err
+		}
+		return tx.Model(website).Update("next_run_at", nextRun(&schedule, time.Now())).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (s *ScheduleService) UpdateSchedule(websiteID int, input ScheduleInput) (*models.Schedule, error) {
+	schedule, err := s.GetSchedule(websiteID)
+	if err != nil {
+		return nil, err
+	}
+	if err := input.apply(schedule); err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(schedule).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Website{}).Where("id = ?", schedule.WebsiteID).
+			Update("next_run_at", nextRun(schedule, time.Now())).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule removes a website's schedule for good, so that a new one can be created.
+func (s *ScheduleService) DeleteSchedule(websiteID int) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("website_id = ? AND deleted_at IS NULL", websiteID).Delete(&models.Schedule{})
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&models.Website{}).Where("id = ?", websiteID).Update("next_run_at", nil).Error
+	})
+}
+
+// RunScheduler starts the scans of scheduled websites as they come due. It never returns.
+func (s *ScheduleService) RunScheduler(pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.startDueScans()
+		<-ticker.C
+	}
+}
+
+func (s *ScheduleService) startDueScans() {
+	now := time.Now()
+
+	var websites []models.Website
+	if err := s.DB.Select("id", "next_run_at", "last_run_at").Where("next_run_at <= ?", now).Find(&websites).Error; err != nil {
+		fmt.Println("Error loading due schedules:", err)
+		return
+	}
+
+	for _, website := range websites {
+		var schedule models.Schedule
+		var next *time.Time
+		if err := s.DB.Where("website_id = ?", website.ID).First(&schedule).Error; err == nil {
+			next = nextRun(&schedule, now)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			fmt.Println("Error loading schedule:", err)
+			continue
+		}
+
+		// Advancing next_run_at from the value we read claims this run, so that
+		// only one server instance starts it. The run is recorded along with the claim,
+		// before the scan is queued, so that the scan cannot race with it.
+		updates := map[string]any{"next_run_at": next}
+		if next != nil {
+			updates["last_run_at"] = now
+		}
+		result := s.DB.Model(&models.Website{}).
+			Where("id = ? AND next_run_at = ?", website.ID, website.NextRunAt).
+			Updates(updates)
+		if result.Error != nil {
+			fmt.Println("Error advancing schedule:", result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 || next == nil {
+			continue
+		}
+
+		if err := s.URLService.StartScanURL(int(website.ID)); err != nil {
+			if errors.Is(err, ErrScanInProgress) || errors.Is(err, ErrScanPaused) {
+				fmt.Printf("Skipping scheduled scan of website %d: %v\n", website.ID, err)
+			} else {
+				fmt.Println("Error starting scheduled scan:", err)
+			}
+			// No scan was started, so the previous run stays the last one
+			if err := s.DB.Model(&models.Website{}).Where("id = ?", website.ID).Update("last_run_at", website.LastRunAt).Error; err != nil {
+				fmt.Println("Error restoring last scheduled run:", err)
+			}
+		}
+	}
+}
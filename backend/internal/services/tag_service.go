@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTag = errors.New("invalid tag")
+	ErrTagExists  = errors.New("a tag with this name already exists")
+)
+
+type TagService struct {
+	DB *gorm.DB
+}
+
+func NewTagService(db *gorm.DB) *TagService {
+	return &TagService{DB: db}
+}
+
+// tagName trims a tag name and checks that it fits the tags table. Commas are not allowed,
+// since they separate the tags of the URL list filter.
+func tagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidTag)
+	}
+	if strings.Contains(name, ",") {
+		return "", fmt.Errorf("%w: name %q contains a comma", ErrInvalidTag, name)
+	}
+	if len(name) > 64 {
+		return "", fmt.Errorf("%w: name %q is longer than 64 characters", ErrInvalidTag, name)
+	}
+	return name, nil
+}
+
+// GetTags returns every tag, sorted by name.
+func (s *TagService) GetTags() ([]models.Tag, error) {
+	var tags []models.Tag
+	if err := s.DB.Order("name asc").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (s *TagService) CreateTag(name string) (*models.Tag, error) {
+	name, err := tagName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(name, 0); err != nil {
+		return nil, err
+	}
+
+	tag := models.Tag{Name: name}
+	if err := s.DB.Create(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// RenameTag changes the name of a tag, keeping the websites it is set on.
+func (s *TagService) RenameTag(id int, name string) (*models.Tag, error) {
+	name, err := tagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var tag models.Tag
+	if err := s.DB.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(name, tag.ID); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Model(&tag).Update("name", name).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (s *TagService) checkNameAvailable(name string, id uint) error {
+	var taken int64
+	if err := s.DB.Model(&models.Tag{}).Where("name = ? AND id <> ?", name, id).Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrTagExists
+	}
+	return nil
+}
+
+// DeleteTag removes a tag from every website and deletes it for good, so that its name can be used again.
+func (s *TagService) DeleteTag(id int) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM website_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Delete(&models.Tag{}, id)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
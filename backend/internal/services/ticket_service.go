@@ -0,0 +1,86 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+	"web-crawler/backend/models"
+)
+
+var ErrInvalidTicket = errors.New("invalid or expired ticket")
+
+// Ticket bodies hold the expiry time, the user ID and a random nonce, followed by the user's role
+const ticketHeaderSize = 8 + 8 + 16
+
+// TicketService issues short-lived tickets that authenticate a WebSocket handshake,
+// for clients such as browsers that cannot set headers on it. Tickets are signed
+// rather than stored, so any server instance sharing the secret accepts them.
+type TicketService struct {
+	Secret []byte
+	TTL    time.Duration
+}
+
+// TicketClaims identify the user a ticket was issued to.
+type TicketClaims struct {
+	UserID uint
+	Role   models.Role
+}
+
+func NewTicketService(secret string, ttl time.Duration) *TicketService {
+	return &TicketService{Secret: []byte(secret), TTL: ttl}
+}
+
+// Issue returns a new ticket for a user and the time it expires.
+func (s *TicketService) Issue(user *models.User) (string, time.Time, error) {
+	expiresAt := time.Now().Add(s.TTL)
+
+	body := make([]byte, ticketHeaderSize, ticketHeaderSize+len(user.Role))
+	binary.BigEndian.PutUint64(body, uint64(expiresAt.Unix()))
+	binary.BigEndian.PutUint64(body[8:], uint64(user.ID))
+	if _, err := rand.Read(body[16:ticketHeaderSize]); err != nil {
+		return "", time.Time{}, err
+	}
+	body = append(body, user.Role...)
+
+	encoding := base64.RawURLEncoding
+	ticket := encoding.EncodeToString(body) + "." + encoding.EncodeToString(s.sign(body))
+	return ticket, expiresAt, nil
+}
+
+// Verify checks that a ticket was issued with this secret and has not expired, and returns
+// the user it was issued to.
+func (s *TicketService) Verify(ticket string) (*TicketClaims, error) {
+	encodedBody, encodedMAC, ok := strings.Cut(ticket, ".")
+	if !ok {
+		return nil, ErrInvalidTicket
+	}
+	body, err := base64.RawURLEncoding.DecodeString(encodedBody)
+	if err != nil || len(body) <= ticketHeaderSize {
+		return nil, ErrInvalidTicket
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, s.sign(body)) {
+		return nil, ErrInvalidTicket
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(body)), 0)
+	if time.Now().After(expiresAt) {
+		return nil, ErrInvalidTicket
+	}
+	return &TicketClaims{
+		UserID: uint(binary.BigEndian.Uint64(body[8:])),
+		Role:   models.Role(body[ticketHeaderSize:]),
+	}, nil
+}
+
+func (s *TicketService) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte("ws-ticket:"))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
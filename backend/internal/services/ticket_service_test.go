@@ -0,0 +1,96 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"web-crawler/backend/models"
+)
+
+func ticketUser(id uint, role models.Role) *models.User {
+	user := &models.User{Role: role}
+	user.ID = id
+	return user
+}
+
+func TestTicketRoundTrip(t *testing.T) {
+	tickets := NewTicketService("secret", time.Minute)
+
+	ticket, expiresAt, err := tickets.Issue(ticketUser(42, models.RoleEditor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if until := time.Until(expiresAt); until <= 0 || until > time.Minute {
+		t.Errorf("ticket expires in %s, want within a minute", until)
+	}
+
+	claims, err := tickets.Verify(ticket)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if claims.UserID != 42 || claims.Role != models.RoleEditor {
+		t.Errorf("Verify = %+v, want user 42 with role editor", claims)
+	}
+
+	other, _, err := tickets.Issue(ticketUser(42, models.RoleEditor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == ticket {
+		t.Error("two tickets issued for the same user are equal")
+	}
+}
+
+func TestTicketRejected(t *testing.T) {
+	tickets := NewTicketService("secret", time.Minute)
+	ticket, _, err := tickets.Issue(ticketUser(42, models.RoleViewer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedBody, encodedMAC, _ := strings.Cut(ticket, ".")
+	body, err := base64.RawURLEncoding.DecodeString(encodedBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reissued rewrites the body of the ticket, keeping its signature
+	reissued := func(change func(body []byte) []byte) string {
+		changed := change(append([]byte(nil), body...))
+		return base64.RawURLEncoding.EncodeToString(changed) + "." + encodedMAC
+	}
+	expired, _, err := NewTicketService("secret", -time.Second).Issue(ticketUser(42, models.RoleViewer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSecret, _, err := NewTicketService("other secret", time.Minute).Issue(ticketUser(42, models.RoleViewer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		ticket string
+	}{
+		{"empty", ""},
+		{"no signature", encodedBody},
+		{"tampered signature", encodedBody + "." + base64.RawURLEncoding.EncodeToString([]byte("not the signature"))},
+		{"signature not base64", encodedBody + ".!!!"},
+		{"body not base64", "!!!." + encodedMAC},
+		{"other user", reissued(func(b []byte) []byte { b[15] = 43; return b })},
+		{"other role", reissued(func(b []byte) []byte { return append(b[:ticketHeaderSize], models.RoleAdmin...) })},
+		{"later expiry", reissued(func(b []byte) []byte { b[7]++; return b })},
+		{"no role", reissued(func(b []byte) []byte { return b[:ticketHeaderSize] })},
+		{"expired", expired},
+		{"signed with another secret", otherSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if claims, err := tickets.Verify(tt.ticket); !errors.Is(err, ErrInvalidTicket) {
+				t.Errorf("Verify = %+v, %v, want ErrInvalidTicket", claims, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProjectNotFound   = errors.New("project not found")
+	ErrInvalidBulkTarget = errors.New("exactly one of ids, projectId or tag is required")
+)
+
+// SetURLProject moves a website into a project, or out of its project when projectID is nil.
+func (s *URLService) SetURLProject(id int, projectID *uint) (*models.Website, error) {
+	website, err := s.GetURLByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if projectID != nil {
+		if err := s.DB.Select("id").First(&models.Project{}, *projectID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	// Only the project column is written, leaving the rest of the row to a scan that may be running
+	if err := s.DB.Model(&models.Website{}).Where("id = ?", website.ID).Update("project_id", projectID).Error; err != nil {
+		return nil, err
+	}
+	return s.GetURLByID(id)
+}
+
+// SetURLTags replaces the tags of a website with the tags named, creating those that do not exist yet.
+func (s *URLService) SetURLTags(id int, names []string) (*models.Website, error) {
+	website, err := s.GetURLByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var unique []string
+	for _, name := range names {
+		name, err := tagName(name)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(unique, name) {
+			unique = append(unique, name)
+		}
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		tags := make([]models.Tag, len(unique))
+		for i, name := range unique {
+			if err := tx.Where(models.Tag{Name: name}).FirstOrCreate(&tags[i]).Error; err != nil {
+				return err
+			}
+		}
+		// A bare website keeps the association from writing back the columns loaded above
+		return tx.Model(&models.Website{Model: gorm.Model{ID: website.ID}}).Association("Tags").Replace(tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetURLByID(id)
+}
+
+// applyGroupFilters narrows query to the websites of a project ("none" for websites without one)
+// and to those having every tag of a comma-separated list.
+func (s *URLService) applyGroupFilters(params GetURLsParams, query *gorm.DB) *gorm.DB {
+	if params.Project == "none" {
+		query = query.Where("project_id IS NULL")
+	} else if params.Project != "" && params.Project != "all" {
+		query = query.Where("project_id = ?", params.Project)
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(params.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		tagged := s.DB.Table("website_tags").
+			Select("website_tags.website_id").
+			Joins("JOIN tags ON tags.id = website_tags.tag_id").
+			Where("tags.name IN ?", tags).
+			Group("website_tags.website_id").
+			Having("COUNT(DISTINCT tags.id) = ?", len(tags))
+		query = query.Where("id IN (?)", tagged)
+	}
+	return query
+}
+
+// BulkTarget selects the websites of a bulk operation: the listed IDs, the websites of a project
+// or the websites with a tag. Exactly one of them is set.
+type BulkTarget struct {
+	IDs       []int
+	ProjectID uint
+	Tag       string
+}
+
+// ResolveBulkTarget returns the IDs of the websites selected by target.
+func (s *URLService) ResolveBulkTarget(target BulkTarget) ([]int, error) {
+	set := 0
+	for _, ok := range []bool{target.IDs != nil, target.ProjectID != 0, target.Tag != ""} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, ErrInvalidBulkTarget
+	}
+
+	var ids []int
+	switch {
+	case target.IDs != nil:
+		return target.IDs, nil
+	case target.ProjectID != 0:
+		if err := s.DB.Select("id").First(&models.Project{}, target.ProjectID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		} else if err != nil {
+			return nil, err
+		}
+		err := s.DB.Model(&models.Website{}).Where("project_id = ?", target.ProjectID).Pluck("id", &ids).Error
+		return ids, err
+	default:
+		err := s.applyGroupFilters(GetURLsParams{Tags: target.Tag}, s.DB.Model(&models.Website{})).Pluck("id", &ids).Error
+		return ids, err
+	}
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxImportRows bounds the number of entries of a single import.
+const MaxImportRows = 10000
+
+var ErrTooManyImportRows = fmt.Errorf("an import is limited to %d rows", MaxImportRows)
+
+// ImportEntry is a URL to import, with the row it was read from.
+type ImportEntry struct {
+	Row   int
+	Value string
+}
+
+type ImportStatus string
+
+const (
+	ImportCreated   ImportStatus = "created"
+	ImportDuplicate ImportStatus = "duplicate"
+	ImportInvalid   ImportStatus = "invalid"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// ImportResult is the outcome of importing a single entry.
+type ImportResult struct {
+	Row       int          `json:"row"`
+	Input     string       `json:"input"`
+	URL       string       `json:"url,omitempty"`
+	Status    ImportStatus `json:"status"`
+	ID        uint         `json:"id,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	Queued    bool         `json:"queued,omitempty"`
+	ScanError string       `json:"scanError,omitempty"`
+}
+
+type ImportSummary struct {
+	Total      int `json:"total"`
+	Created    int `json:"created"`
+	Duplicates int `json:"duplicates"`
+	Invalid    int `json:"invalid"`
+	Failed     int `json:"failed"`
+	Queued     int `json:"queued"`
+}
+
+type ImportReport struct {
+	Summary ImportSummary  `json:"summary"`
+	Results []ImportResult `json:"results"`
+}
+
+// ReadImportCSV reads the URLs of a CSV file: the "url" column when the first row names one,
+// the first column otherwise.
+func ReadImportCSV(r io.Reader) ([]ImportEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var entries []ImportEntry
+	column := 0
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		} else if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first {
+			if header := headerColumn(record, "url"); header >= 0 {
+				column = header
+				continue
+			}
+		}
+		if column >= len(record) || strings.TrimSpace(record[column]) == "" {
+			continue
+		}
+		if len(entries) == MaxImportRows {
+			return nil, ErrTooManyImportRows
+		}
+		entries = append(entries, ImportEntry{Row: line, Value: record[column]})
+	}
+}
+
+func headerColumn(record []string, name string) int {
+	for i, cell := range record {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff")), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ReadImportLines reads one URL per line, skipping blank lines and lines starting with #.
+func ReadImportLines(r io.Reader) ([]ImportEntry, error) {
+	scanner := bufio.NewScanner(r)
+	var entries []ImportEntry
+	for line := 1; scanner.Scan(); line++ {
+		value := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if value == "" || strings.HasPrefix(value, "#") {
+			continue
+		}
+		if len(entries) == MaxImportRows {
+			return nil, ErrTooManyImportRows
+		}
+		entries = append(entries, ImportEntry{Row: line, Value: value})
+	}
+	return entries, scanner.Err()
+}
+
+// ReadImportSitemap reads the page URLs of a sitemap, following sitemap indexes.
+func (s *URLService) ReadImportSitemap(sitemapURL string) ([]ImportEntry, error) {
+	sitemapURL = importURL(sitemapURL)
+	if _, err := s.Normalizer.Normalize(sitemapURL); err != nil {
+		return nil, err
+	}
+	urls, err := s.Crawler.ReadSitemap(sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(urls) > MaxImportRows {
+		return nil, ErrTooManyImportRows
+	}
+
+	entries := make([]ImportEntry, len(urls))
+	for i, u := range urls {
+		entries[i] = ImportEntry{Row: i + 1, Value: u}
+	}
+	return entries, nil
+}
+
+// importURL returns the URL of an import entry, defaulting to https when it has no scheme.
+func importURL(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	return value
+}
+
+// ImportURLs adds the URLs of entries with the given crawl settings, skipping those that already exist,
+// and queues a scan of each added URL when scan is set. It reports the outcome of every entry.
+func (s *URLService) ImportURLs(entries []ImportEntry, settings CrawlSettings, scan bool) ImportReport {
+	report := ImportReport{Results: make([]ImportResult, 0, len(entries))}
+	for _, entry := range entries {
+		result := ImportResult{Row: entry.Row, Input: entry.Value}
+
+		normalized, err := s.Normalizer.Normalize(importURL(entry.Value))
+		if err != nil {
+			result.Status = ImportInvalid
+			result.Error = err.Error()
+			report.Summary.Invalid++
+			report.Results = append(report.Results, result)
+			continue
+		}
+		result.URL = normalized
+
+		website, err := s.CreateURL(normalized, settings)
+		switch {
+		case errors.Is(err, ErrURLAlreadyExists):
+			result.Status = ImportDuplicate
+			report.Summary.Duplicates++
+		case err != nil:
+			result.Status = ImportFailed
+			result.Error = err.Error()
+			report.Summary.Failed++
+		default:
+			result.Status = ImportCreated
+			result.ID = website.ID
+			report.Summary.Created++
+
+			if scan {
+				if err := s.StartScanURL(int(website.ID)); err != nil {
+					result.ScanError = err.Error()
+				} else {
+					result.Queued = true
+					report.Summary.Queued++
+				}
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+	report.Summary.Total = len(entries)
+	return report
+}
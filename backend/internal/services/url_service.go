@@ -1,12 +1,12 @@
 package services
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"sync"
 	"time"
 	"web-crawler/backend/internal/services/crawler"
+	"web-crawler/backend/internal/urlnorm"
 	"web-crawler/backend/internal/websocket"
 	"web-crawler/backend/models"
 
@@ -15,34 +15,91 @@ import (
 
 var (
 	ErrURLAlreadyExists = errors.New("url already exists")
+	ErrNoActiveScan     = errors.New("no active scan found to pause")
+	ErrNoPausedScan     = errors.New("no paused scan found to resume")
 )
 
 type URLService struct {
-	DB             *gorm.DB
-	Hub            *websocket.Hub
-	Crawler        *crawler.Service
-	cancelations   map[uint]chan struct{}
-	cancelationsMu sync.Mutex
+	DB            *gorm.DB
+	Hub           *websocket.Hub
+	Crawler       *crawler.Service
+	Jobs          *JobQueue
+	Webhooks      *WebhookService
+	Alerts        *AlertService
+	Normalizer    *urlnorm.Normalizer
+	stopSignals   map[uint]*crawler.StopSignal
+	stopSignalsMu sync.Mutex
+	wake          chan struct{}
 }
 
-func NewURLService(db *gorm.DB, hub *websocket.Hub, crawler *crawler.Service) *URLService {
+func NewURLService(db *gorm.DB, hub *websocket.Hub, crawlerService *crawler.Service, jobs *JobQueue, webhooks *WebhookService, alerts *AlertService, normalizer *urlnorm.Normalizer) *URLService {
 	return &URLService{
-		DB:           db,
-		Hub:          hub,
-		Crawler:      crawler,
-		cancelations: make(map[uint]chan struct{}),
+		DB:          db,
+		Hub:         hub,
+		Crawler:     crawlerService,
+		Jobs:        jobs,
+		Webhooks:    webhooks,
+		Alerts:      alerts,
+		Normalizer:  normalizer,
+		stopSignals: make(map[uint]*crawler.StopSignal),
+		wake:        make(chan struct{}, 1),
 	}
 }
 
-func (s *URLService) CreateURL(url string) (*models.Website, error) {
+// CrawlSettings holds the per-website crawl configuration. Nil fields are left unchanged.
+type CrawlSettings struct {
+	MaxDepth     *int
+	MaxPages     *int
+	IgnoreRobots *bool
+}
+
+func (cs CrawlSettings) updates() map[string]any {
+	updates := make(map[string]any)
+	if cs.MaxDepth != nil {
+		updates["max_depth"] = *cs.MaxDepth
+	}
+	if cs.MaxPages != nil {
+		updates["max_pages"] = *cs.MaxPages
+	}
+	if cs.IgnoreRobots != nil {
+		updates["ignore_robots"] = *cs.IgnoreRobots
+	}
+	return updates
+}
+
+func (cs CrawlSettings) apply(website *models.Website) {
+	if cs.MaxDepth != nil {
+		website.MaxDepth = *cs.MaxDepth
+	}
+	if cs.MaxPages != nil {
+		website.MaxPages = *cs.MaxPages
+	}
+	if cs.IgnoreRobots != nil {
+		website.IgnoreRobots = *cs.IgnoreRobots
+	}
+}
+
+// CreateURL adds a website for url. It returns ErrURLAlreadyExists when a website has the same
+// normalized URL, and an error wrapping urlnorm.ErrInvalidURL when url cannot be normalized.
+// A trashed website with the same normalized URL is restored instead.
+func (s *URLService) CreateURL(url string, settings CrawlSettings) (*models.Website, error) {
+	normalized, err := s.Normalizer.Normalize(url)
+	if err != nil {
+		return nil, err
+	}
+
 	var existingWebsite models.Website
-	if err := s.DB.Where("url = ?", url).First(&existingWebsite).Error; err == nil {
+	if err := s.DB.Unscoped().Where("normalized_url = ? OR url = ?", normalized, url).First(&existingWebsite).Error; err == nil {
+		if existingWebsite.DeletedAt.Valid {
+			return s.restoreTrashedURL(&existingWebsite, settings)
+		}
 		return nil, ErrURLAlreadyExists
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 
-	website := models.Website{URL: url}
+	website := models.Website{URL: url, NormalizedURL: &normalized}
+	settings.apply(&website)
 	if result := s.DB.Create(&website); result.Error != nil {
 		return nil, result.Error
 	}
@@ -50,6 +107,37 @@ func (s *URLService) CreateURL(url string) (*models.Website, error) {
 	return &website, nil
 }
 
+// BackfillNormalizedURLs sets the normalized URL of websites added before URLs were normalized.
+// A website whose normalized URL is already taken by another one is left without.
+func (s *URLService) BackfillNormalizedURLs() error {
+	var websites []models.Website
+	if err := s.DB.Unscoped().Select("id", "url").Where("normalized_url IS NULL").Find(&websites).Error; err != nil {
+		return err
+	}
+
+	for _, website := range websites {
+		normalized, err := s.Normalizer.Normalize(website.URL)
+		if err != nil {
+			fmt.Printf("Failed to normalize URL of website %d: %v\n", website.ID, err)
+			continue
+		}
+
+		var taken int64
+		if err := s.DB.Unscoped().Model(&models.Website{}).Where("normalized_url = ?", normalized).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			fmt.Printf("Website %d duplicates the normalized URL %s of another website\n", website.ID, normalized)
+			continue
+		}
+
+		if err := s.DB.Unscoped().Model(&models.Website{}).Where("id = ?", website.ID).Update("normalized_url", normalized).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type GetURLsParams struct {
 	Page             int
 	Limit            int
@@ -67,8 +155,12 @@ type GetURLsParams struct {
 	DateCreatedTo    string
 	DateCrawledFrom  string
 	DateCrawledTo    string
-	SortBy           string
-	SortOrder        string
+	// Project is a project ID, or "none" for websites without a project
+	Project string
+	// Tags is a comma-separated list of tag names, all of which a website must have
+	Tags      string
+	SortBy    string
+	SortOrder string
 }
 
 func (s *URLService) GetURLs(params GetURLsParams) ([]models.Website, int64, error) {
@@ -82,16 +174,40 @@ func (s *URLService) GetURLs(params GetURLsParams) ([]models.Website, int64, err
 
 	offset := (params.Page - 1) * params.Limit
 	var websites []models.Website
-	if result := query.Order(s.getOrderBy(params)).Offset(offset).Limit(params.Limit).Find(&websites); result.Error != nil {
+	if result := query.Preload("Project").Preload("Tags").Order(s.getOrderBy(params)).Offset(offset).Limit(params.Limit).Find(&websites); result.Error != nil {
 		return nil, 0, result.Error
 	}
 
 	return websites, totalItems, nil
 }
 
+// ExportURLs calls write with every website matching the filters of params, in the requested order.
+// Websites are read one at a time rather than loaded all at once. Page and Limit are ignored.
+func (s *URLService) ExportURLs(params GetURLsParams, write func(*models.Website) error) error {
+	query := s.DB.Model(&models.Website{})
+	query = s.buildFilterQuery(params, query)
+
+	rows, err := query.Order(s.getOrderBy(params)).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var website models.Website
+		if err := s.DB.ScanRows(rows, &website); err != nil {
+			return err
+		}
+		if err := write(&website); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (s *URLService) GetURLByID(id int) (*models.Website, error) {
 	var website models.Website
-	if err := s.DB.First(&website, id).Error; err != nil {
+	if err := s.DB.Preload("Project").Preload("Tags").First(&website, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, gorm.ErrRecordNotFound
 		}
@@ -100,10 +216,25 @@ func (s *URLService) GetURLByID(id int) (*models.Website, error) {
 	return &website, nil
 }
 
+func (s *URLService) UpdateURLSettings(id int, settings CrawlSettings) (*models.Website, error) {
+	website, err := s.GetURLByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updates := settings.updates(); len(updates) > 0 {
+		if err := s.DB.Model(website).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+		settings.apply(website)
+	}
+	return website, nil
+}
+
 func (s *URLService) DeleteURLByID(id int) error {
-	if result := s.DB.Delete(&models.Website{}, id); result.Error != nil {
-		return result.Error
-	} else if result.RowsAffected == 0 {
+	if deleted, err := s.trashURLs([]int{id}); err != nil {
+		return err
+	} else if deleted == 0 {
 		return gorm.ErrRecordNotFound
 	}
 	return nil
@@ -113,8 +244,48 @@ func (s *URLService) BulkDeleteURLs(ids []int) (int64, error) {
 	if len(ids) == 0 {
 		return 0, nil
 	}
-	result := s.DB.Where("id IN ?", ids).Delete(&models.Website{})
-	return result.RowsAffected, result.Error
+	return s.trashURLs(ids)
+}
+
+// trashURLs moves websites to the trash and cancels their scans in the same transaction, so that
+// a scan cannot be claimed for a trashed website. It returns the number of websites moved.
+func (s *URLService) trashURLs(ids []int) (int64, error) {
+	var deleted int64
+	var cancelled []models.CrawlJob
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if cancelled, err = s.Jobs.CancelWebsites(tx, ids); err != nil {
+			return err
+		}
+		if len(cancelled) > 0 {
+			scanned := make([]uint, len(cancelled))
+			for i, job := range cancelled {
+				scanned[i] = job.WebsiteID
+			}
+			// A website restored later should not look like it is still being scanned
+			if err := tx.Model(&models.Website{}).Where("id IN ?", scanned).Update("status", models.Cancelled).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Where("id IN ?", ids).Delete(&models.Website{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, job := range cancelled {
+		if job.Status == models.JobPaused {
+			if job.CrawlRunID != 0 {
+				s.discardPausedRun(job.CrawlRunID)
+			}
+			continue
+		}
+		s.stopLocalScan(job.WebsiteID, false)
+	}
+	return deleted, nil
 }
 
 func (s *URLService) buildFilterQuery(params GetURLsParams, query *gorm.DB) *gorm.DB {
@@ -141,10 +312,10 @@ func (s *URLService) buildFilterQuery(params GetURLsParams, query *gorm.DB) *gor
 	s.applyRangeFilter(query, "broken_links", params.BrokenLinksMax, "<=")
 	s.applyRangeFilter(query, "created_at", params.DateCreatedFrom, ">=")
 	s.applyRangeFilter(query, "created_at", params.DateCreatedTo, "<=")
-	s.applyRangeFilter(query, "crawled_finished_at", params.DateCrawledFrom, ">=")
-	s.applyRangeFilter(query, "crawled_finished_at", params.DateCrawledTo, "<=")
+	s.applyRangeFilter(query, "crawl_finished_at", params.DateCrawledFrom, ">=")
+	s.applyRangeFilter(query, "crawl_finished_at", params.DateCrawledTo, "<=")
 
-	return query
+	return s.applyGroupFilters(params, query)
 }
 
 func (s *URLService) applyRangeFilter(query *gorm.DB, dbField, value, operator string) {
@@ -186,48 +357,255 @@ func (s *URLService) StartScanURL(id int) error {
 		return err
 	}
 
-	s.cancelationsMu.Lock()
-	if _, exists := s.cancelations[website.ID]; exists {
-		s.cancelationsMu.Unlock()
-		return errors.New("scan already in progress")
+	if _, err := s.Jobs.Enqueue(website.ID); err != nil {
+		return err
 	}
-	cancelChan := make(chan struct{})
-	s.cancelations[website.ID] = cancelChan
-	s.cancelationsMu.Unlock()
-
-	go s.performScan(&website, cancelChan)
+	s.updateScanStatus(website.ID, models.Queued)
+	s.wakeWorkers()
 
 	return nil
 }
 
-func (s *URLService) performScan(website *models.Website, cancelChan chan struct{}) {
+// RunScanWorkers claims queued scan jobs and runs them on a pool of at most workers
+// concurrent scans. Jobs stay queued until a worker is free. It never returns.
+func (s *URLService) RunScanWorkers(workers int, pollInterval time.Duration) {
+	if workers < 1 {
+		workers = 1
+	}
+	slots := make(chan struct{}, workers)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatchJobs(slots)
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *URLService) wakeWorkers() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchJobs hands pending jobs to free workers until either runs out.
+func (s *URLService) dispatchJobs(slots chan struct{}) {
+	if requeued, err := s.Jobs.RequeueExpired(); err != nil {
+		fmt.Println("Error requeuing expired scan jobs:", err)
+	} else if requeued > 0 {
+		fmt.Printf("Requeued %d scan jobs with expired leases\n", requeued)
+	}
+
+	for {
+		select {
+		case slots <- struct{}{}:
+		default:
+			return
+		}
+
+		job, err := s.Jobs.Claim()
+		if err != nil || job == nil {
+			<-slots
+			if err != nil {
+				fmt.Println("Error claiming scan job:", err)
+			}
+			return
+		}
+
+		go func() {
+			defer func() {
+				<-slots
+				s.wakeWorkers()
+			}()
+			s.runJob(job)
+		}()
+	}
+}
+
+func (s *URLService) runJob(job *models.CrawlJob) {
+	var website models.Website
+	if err := s.DB.First(&website, job.WebsiteID).Error; err != nil {
+		fmt.Println("Error loading website for scan job:", err)
+		s.finishJob(job, models.JobCancelled)
+		return
+	}
+
+	s.stopSignalsMu.Lock()
+	stop := crawler.NewStopSignal()
+	s.stopSignals[website.ID] = stop
+	s.stopSignalsMu.Unlock()
+
+	// The lease is lost when the job was cancelled or paused from another instance
+	stopKeepAlive := s.Jobs.KeepAlive(job, func() {
+		status, err := s.Jobs.Status(job.ID)
+		s.stopLocalScan(website.ID, err == nil && status == models.JobPaused)
+	})
+	defer stopKeepAlive()
+
+	if status := s.performScan(&website, job, stop); status != models.Paused {
+		s.finishJob(job, models.JobDone)
+	}
+}
+
+func (s *URLService) finishJob(job *models.CrawlJob, status models.JobStatus) {
+	if err := s.Jobs.Finish(job, status); err != nil {
+		fmt.Println("Error finishing scan job:", err)
+	}
+}
+
+// RecoverScans reconciles the state left behind by a previous process: running jobs whose lease
+// expired are requeued, website statuses are matched to their jobs and interrupted runs are closed.
+func (s *URLService) RecoverScans() error {
+	if _, err := s.Jobs.RequeueExpired(); err != nil {
+		return err
+	}
+
+	var websites []models.Website
+	if err := s.DB.Select("id", "status").Where("status IN ?", []models.StatusType{models.Queued, models.Crawling}).Find(&websites).Error; err != nil {
+		return err
+	}
+	for _, website := range websites {
+		job, err := s.Jobs.Active(website.ID)
+		if err == nil && job == nil {
+			// A worker that died while pausing leaves a paused job without a run to resume
+			job, err = s.Jobs.Paused(website.ID)
+		}
+		if err != nil {
+			return err
+		}
+
+		status := website.Status
+		switch {
+		case job == nil && website.Status == models.Crawling:
+			status = models.Failed
+		case job != nil && job.Status == models.JobPending:
+			status = models.Queued
+		case job != nil && job.Status == models.JobRunning:
+			status = models.Crawling
+		case job != nil && job.Status == models.JobPaused:
+			status = models.Paused
+		}
+		if status != website.Status {
+			if err := s.DB.Model(&website).Update("status", status).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	runningJobs := s.DB.Model(&models.CrawlJob{}).Select("website_id").Where("status = ?", models.JobRunning)
+	return s.DB.Model(&models.CrawlRun{}).
+		Where("status = ? AND website_id NOT IN (?)", models.Crawling, runningJobs).
+		Updates(map[string]any{
+			"status":         models.Failed,
+			"finished_at":    time.Now(),
+			"failure_reason": "interrupted by a server restart",
+		}).Error
+}
+
+// performScan crawls a website for a claimed job and returns the final scan status.
+func (s *URLService) performScan(website *models.Website, job *models.CrawlJob, stop *crawler.StopSignal) models.StatusType {
 	defer func() {
-		s.cancelationsMu.Lock()
-		delete(s.cancelations, website.ID)
-		s.cancelationsMu.Unlock()
+		s.stopSignalsMu.Lock()
+		delete(s.stopSignals, website.ID)
+		s.stopSignalsMu.Unlock()
 	}()
 
-	s.DB.Model(website).Update("crawl_started_at", time.Now())
+	run, err := s.startRun(website, job)
+	if err != nil {
+		fmt.Println("Error starting crawl run:", err)
+		s.updateScanStatus(website.ID, models.Failed)
+		return models.Failed
+	}
 	s.updateScanStatus(website.ID, models.Crawling)
 
-	err := s.Crawler.ProcessURL(website, cancelChan)
+	err = s.Crawler.ProcessURL(website, run.ID, stop, func(progress crawler.Progress) {
+		s.broadcastProgress(website.ID, progress)
+	})
+	if errors.Is(err, crawler.ErrCrawlPaused) {
+		// The job must be paused before clients hear about it, so that they can resume it
+		paused, err := s.Jobs.Pause(job, run.ID)
+		if err != nil {
+			fmt.Println("Error pausing scan job:", err)
+		}
+		if !paused {
+			// The scan was cancelled while it was pausing
+			s.discardPausedRun(run.ID)
+			s.updateScanStatus(website.ID, models.Cancelled)
+			return models.Cancelled
+		}
+		if err := s.DB.Model(run).Update("status", models.Paused).Error; err != nil {
+			fmt.Println("Error saving crawl run:", err)
+		}
+		s.updateScanStatus(website.ID, models.Paused)
+		return models.Paused
+	}
+
+	status := models.Completed
 	now := time.Now()
 	website.CrawlFinishedAt = &now
+	website.FailureCode, website.FailureReason = "", ""
 	if err != nil {
 		if errors.Is(err, crawler.ErrCrawlCancelled) {
-			s.updateScanStatus(website.ID, models.Cancelled)
+			status = models.Cancelled
 		} else {
-			s.updateScanStatus(website.ID, models.Failed)
+			status = models.Failed
+			website.FailureCode = crawler.FailureCode(err)
+			website.FailureReason = err.Error()
 		}
-		return
+	}
+	if saveErr := s.Crawler.SaveResult(website); saveErr != nil && err == nil {
+		status = models.Failed
+		err = saveErr
 	}
 
-	if err := s.DB.Save(website).Error; err != nil {
-		s.updateScanStatus(website.ID, models.Failed)
-		return
+	s.finishRun(run, website, status, err)
+	s.updateScanStatus(website.ID, status)
+	s.Alerts.EvaluateScan(website, run)
+	s.Webhooks.NotifyScanFinished(website, run)
+	return status
+}
+
+// startRun continues the crawl run of a resumed job, or starts a new run.
+func (s *URLService) startRun(website *models.Website, job *models.CrawlJob) (*models.CrawlRun, error) {
+	if job.CrawlRunID != 0 {
+		var run models.CrawlRun
+		err := s.DB.First(&run, job.CrawlRunID).Error
+		if err == nil {
+			run.Status = models.Crawling
+			return &run, s.DB.Model(&run).Update("status", models.Crawling).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	startedAt := time.Now()
+	s.DB.Model(website).Update("crawl_started_at", startedAt)
+
+	run := models.CrawlRun{WebsiteID: website.ID, Status: models.Crawling, StartedAt: startedAt}
+	if err := s.DB.Create(&run).Error; err != nil {
+		return nil, err
 	}
+	return &run, nil
+}
 
-	s.updateScanStatus(website.ID, models.Completed)
+// finishRun stores the final status and a snapshot of the website metrics on a crawl run.
+func (s *URLService) finishRun(run *models.CrawlRun, website *models.Website, status models.StatusType, err error) {
+	run.Status = status
+	run.FinishedAt = website.CrawlFinishedAt
+	run.CrawlMetrics = website.CrawlMetrics
+	if err != nil && status == models.Failed {
+		run.FailureCode = crawler.FailureCode(err)
+		run.FailureReason = err.Error()
+	}
+	if err := s.DB.Save(run).Error; err != nil {
+		fmt.Println("Error saving crawl run:", err)
+	}
 }
 
 func (s *URLService) updateScanStatus(id uint, status models.StatusType) {
@@ -236,25 +614,124 @@ func (s *URLService) updateScanStatus(id uint, status models.StatusType) {
 		return
 	}
 
-	updateData := map[string]any{"id": id, "status": status}
-	message, err := json.Marshal(updateData)
-	if err != nil {
-		fmt.Println("Error marshalling scan status:", err)
-		return
-	}
-	s.Hub.Broadcast(message)
+	s.Hub.Publish(websocket.NewEvent(websocket.EventScanStatus, id, map[string]any{"status": status}))
+}
+
+// broadcastProgress tells clients how far a running scan has come.
+func (s *URLService) broadcastProgress(id uint, progress crawler.Progress) {
+	s.Hub.Publish(websocket.NewEvent(websocket.EventScanProgress, id, progress))
 }
 
 func (s *URLService) CancelScanURL(id int) error {
 	uintID := uint(id)
-	s.cancelationsMu.Lock()
-	defer s.cancelationsMu.Unlock()
+	if s.stopLocalScan(uintID, false) {
+		return nil
+	}
 
-	if cancelChan, exists := s.cancelations[uintID]; exists {
-		close(cancelChan)
-		delete(s.cancelations, uintID)
+	// A queued job may be a resumed one, whose paused run is closed along with it
+	job, err := s.Jobs.CancelPending(uintID)
+	if err == nil && job == nil {
+		job, err = s.Jobs.CancelPaused(uintID)
+	}
+	if err != nil {
+		return err
+	}
+	if job != nil {
+		if job.CrawlRunID != 0 {
+			s.discardPausedRun(job.CrawlRunID)
+		}
+		s.updateScanStatus(uintID, models.Cancelled)
 		return nil
 	}
 
+	// A scan running on another instance stops once its worker fails to renew the lease
+	job, err = s.Jobs.Active(uintID)
+	if err != nil {
+		return err
+	}
+	if job != nil {
+		return s.Jobs.Finish(job, models.JobCancelled)
+	}
+
 	return errors.New("no active scan found to cancel")
 }
+
+// discardPausedRun closes the crawl run of a paused scan that will not be resumed.
+func (s *URLService) discardPausedRun(runID uint) {
+	err := s.DB.Model(&models.CrawlRun{}).Where("id = ?", runID).Updates(map[string]any{
+		"status":      models.Cancelled,
+		"finished_at": time.Now(),
+	}).Error
+	if err != nil {
+		fmt.Println("Error closing paused crawl run:", err)
+	}
+	if err := s.Crawler.DiscardCheckpoint(runID); err != nil {
+		fmt.Println("Error deleting crawl checkpoint:", err)
+	}
+}
+
+// PauseScanURL pauses a queued or running scan. A running scan saves its progress and frees its worker.
+func (s *URLService) PauseScanURL(id int) error {
+	uintID := uint(id)
+	if s.stopLocalScan(uintID, true) {
+		return nil
+	}
+
+	paused, err := s.Jobs.PausePending(uintID)
+	if err != nil {
+		return err
+	}
+	if paused {
+		s.updateScanStatus(uintID, models.Paused)
+		return nil
+	}
+
+	// A scan running on another instance pauses once its worker fails to renew the lease
+	job, err := s.Jobs.Active(uintID)
+	if err != nil {
+		return err
+	}
+	if job != nil {
+		return s.Jobs.RequestPause(job)
+	}
+
+	return ErrNoActiveScan
+}
+
+// ResumeScanURL queues a paused scan again. It continues from where it was paused.
+func (s *URLService) ResumeScanURL(id int) error {
+	var website models.Website
+	if err := s.DB.First(&website, id).Error; err != nil {
+		return err
+	}
+
+	resumed, err := s.Jobs.Resume(website.ID)
+	if err != nil {
+		return err
+	}
+	if !resumed {
+		return ErrNoPausedScan
+	}
+	s.updateScanStatus(website.ID, models.Queued)
+	s.wakeWorkers()
+
+	return nil
+}
+
+// stopLocalScan cancels or pauses a scan running in this process. It reports whether one was found.
+func (s *URLService) stopLocalScan(id uint, pause bool) bool {
+	s.stopSignalsMu.Lock()
+	defer s.stopSignalsMu.Unlock()
+
+	stop, exists := s.stopSignals[id]
+	if !exists {
+		return false
+	}
+	if pause {
+		stop.Pause()
+	} else {
+		stop.Cancel()
+	}
+	delete(s.stopSignals, id)
+	return true
+}
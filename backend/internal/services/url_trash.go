@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrURLNotTrashed = errors.New("url is not in the trash, delete it first")
+
+// websiteChildren are the models holding rows of a website, deleted along with it when it is purged.
+var websiteChildren = []any{
+	&models.Link{},
+	&models.Page{},
+	&models.SitemapIssue{},
+	&models.CrawlCheckpoint{},
+	&models.CrawlRun{},
+	&models.CrawlJob{},
+	&models.Schedule{},
+	&models.Alert{},
+	&models.AlertRule{},
+	&models.WebhookDelivery{},
+}
+
+// GetTrashedURLs returns the deleted websites, most recently deleted first.
+func (s *URLService) GetTrashedURLs(page, limit int) ([]models.Website, int64, error) {
+	query := s.DB.Unscoped().Model(&models.Website{}).Where("deleted_at IS NOT NULL")
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var websites []models.Website
+	offset := (page - 1) * limit
+	if err := query.Order("deleted_at desc").Offset(offset).Limit(limit).Find(&websites).Error; err != nil {
+		return nil, 0, err
+	}
+	return websites, totalItems, nil
+}
+
+// RestoreURL takes a website out of the trash.
+func (s *URLService) RestoreURL(id int) (*models.Website, error) {
+	result := s.DB.Unscoped().Model(&models.Website{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.GetURLByID(id)
+}
+
+// restoreTrashedURL takes a trashed website out of the trash when it is added again,
+// applying the crawl settings given with it.
+func (s *URLService) restoreTrashedURL(website *models.Website, settings CrawlSettings) (*models.Website, error) {
+	updates := settings.updates()
+	updates["deleted_at"] = nil
+	if err := s.DB.Unscoped().Model(&models.Website{}).Where("id = ?", website.ID).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	fmt.Printf("Restored website %d from the trash\n", website.ID)
+	return s.GetURLByID(int(website.ID))
+}
+
+// PurgeURL permanently deletes a trashed website together with its pages, links, runs and
+// every other row belonging to it. A website that is still being scanned cannot be purged.
+func (s *URLService) PurgeURL(id int) error {
+	var website models.Website
+	if err := s.DB.Unscoped().Select("id", "deleted_at").First(&website, id).Error; err != nil {
+		return err
+	}
+	if !website.DeletedAt.Valid {
+		return ErrURLNotTrashed
+	}
+
+	job, err := s.Jobs.Active(website.ID)
+	if err != nil {
+		return err
+	} else if job != nil {
+		return ErrScanInProgress
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		return purgeWebsites(tx, []uint{website.ID})
+	})
+}
+
+// PurgeTrash permanently deletes the websites that were trashed longer than retention ago,
+// except those still being scanned, and returns how many were purged.
+func (s *URLService) PurgeTrash(retention time.Duration) (int, error) {
+	var ids []uint
+	err := s.DB.Unscoped().Model(&models.Website{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", time.Now().Add(-retention)).
+		Where("id NOT IN (?)", s.DB.Model(&models.CrawlJob{}).Select("website_id").
+			Where("status IN ?", []models.JobStatus{models.JobPending, models.JobRunning})).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		return purgeWebsites(tx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// RunTrashPurge purges the websites trashed longer than retention ago, every interval.
+func (s *URLService) RunTrashPurge(retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := s.PurgeTrash(retention); err != nil {
+			fmt.Println("Error purging trash:", err)
+		} else if purged > 0 {
+			fmt.Printf("Purged %d websites from the trash\n", purged)
+		}
+		<-ticker.C
+	}
+}
+
+// purgeWebsites deletes the websites with the given IDs and their rows. The foreign keys are
+// not declared in the schema, so the rows are deleted explicitly rather than by cascade.
+func purgeWebsites(tx *gorm.DB, ids []uint) error {
+	for _, child := range websiteChildren {
+		if err := tx.Unscoped().Where("website_id IN ?", ids).Delete(child).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Exec("DELETE FROM website_tags WHERE website_id IN ?", ids).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Website{}).Error
+}
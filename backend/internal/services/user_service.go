@@ -0,0 +1,346 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+	"web-crawler/backend/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	minPasswordLength = 8
+	// maxPasswordLength is the longest password bcrypt hashes in full
+	maxPasswordLength = 72
+	// sessionTouchInterval limits how often the last use of a session is written
+	sessionTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidUser        = errors.New("invalid user")
+	ErrUserExists         = errors.New("a user with this email already exists")
+	ErrLastAdmin          = errors.New("the last enabled admin cannot be deleted, disabled or demoted")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidSession     = errors.New("invalid or expired session")
+)
+
+// dummyPasswordHash is compared against when signing in with an unknown email, so that
+// unknown and known emails take as long to reject.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+
+type UserService struct {
+	DB         *gorm.DB
+	SessionTTL time.Duration
+}
+
+func NewUserService(db *gorm.DB, sessionTTL time.Duration) *UserService {
+	return &UserService{DB: db, SessionTTL: sessionTTL}
+}
+
+// UserInput describes a user. An empty Password keeps the current password on update.
+type UserInput struct {
+	Email    string
+	Name     string
+	Password string
+	Role     models.Role
+	Disabled bool
+}
+
+func (in UserInput) apply(user *models.User) error {
+	email := strings.ToLower(strings.TrimSpace(in.Email))
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return fmt.Errorf("%w: email must be a plain email address", ErrInvalidUser)
+	}
+	if !slices.Contains(models.Roles, in.Role) {
+		return fmt.Errorf("%w: role must be one of viewer, editor or admin", ErrInvalidUser)
+	}
+	if in.Password != "" || user.PasswordHash == "" {
+		hash, err := hashPassword(in.Password)
+		if err != nil {
+			return err
+		}
+		user.PasswordHash = hash
+	}
+
+	user.Email = email
+	user.Name = strings.TrimSpace(in.Name)
+	user.Role = in.Role
+	user.Disabled = in.Disabled
+	return nil
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return "", fmt.Errorf("%w: password must be %d to %d characters long", ErrInvalidUser, minPasswordLength, maxPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (s *UserService) GetUsers(page, limit int) ([]models.User, int64, error) {
+	query := s.DB.Model(&models.User{})
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	var users []models.User
+	if err := query.Order("email asc").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, totalItems, nil
+}
+
+func (s *UserService) GetUser(id int) (*models.User, error) {
+	var user models.User
+	if err := s.DB.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *UserService) CreateUser(input UserInput) (*models.User, error) {
+	var user models.User
+	if err := input.apply(&user); err != nil {
+		return nil, err
+	}
+	if err := s.checkEmailAvailable(user.Email, 0); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUser changes a user. Disabling the user, changing its role or its password signs it out everywhere.
+func (s *UserService) UpdateUser(id int, input UserInput) (*models.User, error) {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *user
+	if err := input.apply(user); err != nil {
+		return nil, err
+	}
+	if err := s.checkEmailAvailable(user.Email, user.ID); err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if isActiveAdmin(&before) && !isActiveAdmin(user) {
+			if err := checkOtherAdmins(tx, user.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		if user.Disabled || user.Role != before.Role || user.PasswordHash != before.PasswordHash {
+			return tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Session{}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser deletes a user and its sessions. The user is deleted for good rather than
+// soft-deleted, so that its email can be used again.
+func (s *UserService) DeleteUser(id int) error {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if isActiveAdmin(user) {
+			if err := checkOtherAdmins(tx, user.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(user).Error
+	})
+}
+
+func (s *UserService) checkEmailAvailable(email string, id uint) error {
+	var taken int64
+	if err := s.DB.Model(&models.User{}).Where("email = ? AND id <> ?", email, id).Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrUserExists
+	}
+	return nil
+}
+
+func isActiveAdmin(user *models.User) bool {
+	return user.Role == models.RoleAdmin && !user.Disabled
+}
+
+// checkOtherAdmins makes sure an enabled admin other than the user with the given ID remains.
+func checkOtherAdmins(tx *gorm.DB, id uint) error {
+	var admins int64
+	err := tx.Model(&models.User{}).
+		Where("role = ? AND disabled = ? AND id <> ?", models.RoleAdmin, false, id).
+		Count(&admins).Error
+	if err != nil {
+		return err
+	}
+	if admins == 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// BootstrapAdmin creates an admin with the given email and password when there are no users yet.
+func (s *UserService) BootstrapAdmin(email, password string) error {
+	var users int64
+	if err := s.DB.Model(&models.User{}).Count(&users).Error; err != nil {
+		return err
+	}
+	if users > 0 {
+		return nil
+	}
+
+	user, err := s.CreateUser(UserInput{Email: email, Name: "Admin", Password: password, Role: models.RoleAdmin})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created admin user %s\n", user.Email)
+	return nil
+}
+
+// Login checks the credentials of a user and starts a session. The returned token authenticates
+// the session until it expires or the user signs out.
+func (s *UserService) Login(email, password string) (string, *models.Session, *models.User, error) {
+	var user models.User
+	err := s.DB.Where("email = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return "", nil, nil, ErrInvalidCredentials
+	} else if err != nil {
+		return "", nil, nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil || user.Disabled {
+		return "", nil, nil, ErrInvalidCredentials
+	}
+
+	token, session, err := s.createSession(user.ID)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	now := time.Now()
+	if err := s.DB.Model(&user).Update("last_login_at", now).Error; err != nil {
+		fmt.Printf("Failed to record login of user %d: %v\n", user.ID, err)
+	}
+	if err := s.DB.Unscoped().Where("expires_at <= ?", now).Delete(&models.Session{}).Error; err != nil {
+		fmt.Println("Error deleting expired sessions:", err)
+	}
+	return token, session, &user, nil
+}
+
+func (s *UserService) createSession(userID uint) (string, *models.Session, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	now := time.Now()
+	session := models.Session{
+		UserID:     userID,
+		TokenHash:  hashToken(token),
+		ExpiresAt:  now.Add(s.SessionTTL),
+		LastUsedAt: now,
+	}
+	if err := s.DB.Create(&session).Error; err != nil {
+		return "", nil, err
+	}
+	return token, &session, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticate returns the user signed in with a session token.
+func (s *UserService) Authenticate(token string) (*models.User, error) {
+	if token == "" {
+		return nil, ErrInvalidSession
+	}
+
+	var session models.Session
+	err := s.DB.Where("token_hash = ? AND expires_at > ?", hashToken(token), time.Now()).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidSession
+	} else if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.DB.First(&user, session.UserID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidSession
+	} else if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, ErrInvalidSession
+	}
+
+	if time.Since(session.LastUsedAt) > sessionTouchInterval {
+		if err := s.DB.Model(&session).Update("last_used_at", time.Now()).Error; err != nil {
+			fmt.Printf("Failed to record use of session %d: %v\n", session.ID, err)
+		}
+	}
+	return &user, nil
+}
+
+// Logout ends the session of a token.
+func (s *UserService) Logout(token string) error {
+	return s.DB.Unscoped().Where("token_hash = ?", hashToken(token)).Delete(&models.Session{}).Error
+}
+
+// ChangePassword sets a new password for a user after checking the current one, and ends
+// every other session of the user.
+func (s *UserService) ChangePassword(userID uint, token, currentPassword, newPassword string) error {
+	var user models.User
+	if err := s.DB.First(&user, userID).Error; err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)) != nil {
+		return ErrInvalidCredentials
+	}
+	hash, err := hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password_hash", hash).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ? AND token_hash <> ?", user.ID, hashToken(token)).Delete(&models.Session{}).Error
+	})
+}
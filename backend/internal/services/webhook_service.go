@@ -0,0 +1,429 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"web-crawler/backend/internal/types"
+	"web-crawler/backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// webhookBatchSize is the number of due deliveries attempted at once
+	webhookBatchSize = 10
+	// webhookMaxDelay caps the wait between two attempts of a delivery
+	webhookMaxDelay = time.Hour
+	// webhookResponseLimit is how much of a response body is kept in the delivery log
+	webhookResponseLimit = 1024
+)
+
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+type WebhookService struct {
+	DB             *gorm.DB
+	Client         *http.Client
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+
+	wake chan struct{}
+}
+
+func NewWebhookService(db *gorm.DB, timeout time.Duration, maxAttempts int, retryBaseDelay time.Duration) *WebhookService {
+	return &WebhookService{
+		DB: db,
+		Client: &http.Client{
+			Timeout: timeout,
+			// A redirect is reported as a failed delivery rather than followed, since
+			// following it would resend the payload as a GET
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		MaxAttempts:    max(maxAttempts, 1),
+		RetryBaseDelay: retryBaseDelay,
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+// WebhookInput describes a webhook. An empty Secret is generated on create and kept on update.
+type WebhookInput struct {
+	URL     string
+	Secret  string
+	Events  []string
+	Enabled bool
+}
+
+func (in WebhookInput) apply(webhook *models.Webhook) error {
+	u, err := url.Parse(in.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if len(in.Events) == 0 {
+		return fmt.Errorf("%w: at least one event is required", ErrInvalidWebhook)
+	}
+	events := make(types.StringList, 0, len(in.Events))
+	for _, event := range in.Events {
+		if !slices.Contains(models.WebhookEvents, models.WebhookEvent(event)) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, event)
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	if in.Secret != "" {
+		webhook.Secret = in.Secret
+	} else if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+	webhook.URL = in.URL
+	webhook.Events = events
+	webhook.Enabled = in.Enabled
+	return nil
+}
+
+func (s *WebhookService) GetWebhooks(page, limit int) ([]models.Webhook, int64, error) {
+	query := s.DB.Model(&models.Webhook{})
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	var webhooks []models.Webhook
+	if err := query.Order("id desc").Offset(offset).Limit(limit).Find(&webhooks).Error; err != nil {
+		return nil, 0, err
+	}
+	return webhooks, totalItems, nil
+}
+
+func (s *WebhookService) GetWebhook(id int) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := s.DB.First(&webhook, id).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (s *WebhookService) CreateWebhook(input WebhookInput) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := input.apply(&webhook); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Create(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (s *WebhookService) UpdateWebhook(id int, input WebhookInput) (*models.Webhook, error) {
+	webhook, err := s.GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := input.apply(webhook); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Save(webhook).Error; err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook deletes a webhook along with its delivery log, so that pending deliveries are dropped.
+func (s *WebhookService) DeleteWebhook(id int) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Webhook{}, id)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error
+	})
+}
+
+// GetDeliveries returns the delivery log of a webhook, newest first, optionally only deliveries with the given status.
+func (s *WebhookService) GetDeliveries(webhookID int, status string, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.GetWebhook(webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	query := s.DB.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("id desc").Offset(offset).Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, totalItems, nil
+}
+
+// webhookPayload is the JSON body posted to webhooks.
+type webhookPayload struct {
+	Event     models.WebhookEvent `json:"event"`
+	CreatedAt time.Time           `json:"createdAt"`
+	Website   webhookWebsite      `json:"website"`
+	Run       *models.CrawlRun    `json:"run"`
+	// PreviousBrokenLinks is the number of broken links found by the previous completed run
+	PreviousBrokenLinks *int `json:"previousBrokenLinks,omitempty"`
+}
+
+type webhookWebsite struct {
+	ID  uint   `json:"id"`
+	URL string `json:"url"`
+}
+
+// NotifyScanFinished queues the deliveries of the events raised by a finished crawl run:
+// crawl.completed or crawl.failed, and broken_links.increased when a completed run found
+// more broken links than the previous completed run of the website.
+func (s *WebhookService) NotifyScanFinished(website *models.Website, run *models.CrawlRun) {
+	var events []models.WebhookEvent
+	var previousBrokenLinks *int
+	switch run.Status {
+	case models.Completed:
+		events = append(events, models.WebhookCrawlCompleted)
+
+		var previous []models.CrawlRun
+		err := s.DB.Select("id", "broken_links").
+			Where("website_id = ? AND status = ? AND id < ?", website.ID, models.Completed, run.ID).
+			Order("id desc").Limit(1).Find(&previous).Error
+		if err != nil {
+			fmt.Println("Error loading previous crawl run:", err)
+		} else if len(previous) > 0 && run.BrokenLinks > previous[0].BrokenLinks {
+			events = append(events, models.WebhookBrokenLinksIncreased)
+			previousBrokenLinks = &previous[0].BrokenLinks
+		}
+	case models.Failed:
+		events = append(events, models.WebhookCrawlFailed)
+	default:
+		return
+	}
+
+	var webhooks []models.Webhook
+	if err := s.DB.Where("enabled = ?", true).Find(&webhooks).Error; err != nil {
+		fmt.Println("Error loading webhooks:", err)
+		return
+	}
+
+	now := time.Now()
+	var deliveries []models.WebhookDelivery
+	for _, event := range events {
+		payload, err := json.Marshal(webhookPayload{
+			Event:               event,
+			CreatedAt:           now,
+			Website:             webhookWebsite{ID: website.ID, URL: website.URL},
+			Run:                 run,
+			PreviousBrokenLinks: previousBrokenLinks,
+		})
+		if err != nil {
+			fmt.Println("Error marshalling webhook payload:", err)
+			continue
+		}
+		for _, webhook := range webhooks {
+			if !slices.Contains(webhook.Events, string(event)) {
+				continue
+			}
+			deliveries = append(deliveries, models.WebhookDelivery{
+				WebhookID:     webhook.ID,
+				Event:         event,
+				WebsiteID:     website.ID,
+				CrawlRunID:    run.ID,
+				Payload:       string(payload),
+				Status:        models.DeliveryPending,
+				NextAttemptAt: &now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	if err := s.DB.Create(&deliveries).Error; err != nil {
+		fmt.Println("Error queueing webhook deliveries:", err)
+		return
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// RunDeliveries attempts due webhook deliveries as they come up. It never returns.
+func (s *WebhookService) RunDeliveries(pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// A full batch suggests more deliveries are due
+		for s.deliverDue() == webhookBatchSize {
+			continue
+		}
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// deliverDue attempts a batch of due deliveries and returns how many it attempted.
+func (s *WebhookService) deliverDue() int {
+	deliveries, err := s.claimDue()
+	if err != nil {
+		fmt.Println("Error claiming webhook deliveries:", err)
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.deliver(&deliveries[i])
+		}()
+	}
+	wg.Wait()
+	return len(deliveries)
+}
+
+// claimDue picks due deliveries and postpones them past the delivery timeout,
+// so that no other server instance attempts them at the same time.
+func (s *WebhookService) claimDue() ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+			Order("next_attempt_at asc").Limit(webhookBatchSize).Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(2*s.Client.Timeout)).Error
+	})
+	return deliveries, err
+}
+
+// deliver makes one attempt at a delivery and records its outcome.
+func (s *WebhookService) deliver(delivery *models.WebhookDelivery) {
+	var webhook models.Webhook
+	if err := s.DB.First(&webhook, delivery.WebhookID).Error; err != nil {
+		// A deleted webhook's deliveries are deleted with it
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			fmt.Println("Error loading webhook:", err)
+		}
+		return
+	}
+
+	attempts := delivery.Attempts + 1
+	updates := map[string]any{"attempts": attempts}
+	var err error
+	if !webhook.Enabled {
+		err = errors.New("webhook is disabled")
+		updates["status"] = models.DeliveryFailed
+		updates["next_attempt_at"] = nil
+	} else {
+		var status int
+		var body string
+		status, body, err = s.post(&webhook, delivery)
+		updates["response_status"] = status
+		updates["response_body"] = body
+		switch {
+		case err == nil:
+			now := time.Now()
+			updates["status"] = models.DeliverySucceeded
+			updates["delivered_at"] = &now
+			updates["next_attempt_at"] = nil
+		case attempts >= s.MaxAttempts:
+			updates["status"] = models.DeliveryFailed
+			updates["next_attempt_at"] = nil
+		default:
+			updates["next_attempt_at"] = time.Now().Add(s.retryDelay(attempts))
+		}
+	}
+
+	updates["error"] = ""
+	if err != nil {
+		updates["error"] = err.Error()
+	}
+	if err := s.DB.Model(delivery).Updates(updates).Error; err != nil {
+		fmt.Println("Error saving webhook delivery:", err)
+	}
+}
+
+// post sends a delivery's payload to its webhook, signed with the webhook's secret. It returns the
+// response status and the start of the response body, and an error unless the status is 2xx.
+func (s *WebhookService) post(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", string(delivery.Event))
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+signWebhook(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// signWebhook returns the hex encoded HMAC-SHA256 of the timestamp and payload, joined by a dot.
+// Signing the timestamp lets receivers reject replayed deliveries.
+func signWebhook(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryDelay returns how long to wait before the attempt following the given number of attempts.
+func (s *WebhookService) retryDelay(attempts int) time.Duration {
+	delay := s.RetryBaseDelay << (attempts - 1)
+	if delay <= 0 || delay > webhookMaxDelay {
+		delay = webhookMaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// RedirectHop is a single redirect followed while resolving a link.
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+}
+
+// RedirectHops is a custom type for []RedirectHop to handle JSON serialization.
+type RedirectHops []RedirectHop
+
+func (h RedirectHops) Value() (driver.Value, error) {
+	if h == nil {
+		return json.Marshal(make([]RedirectHop, 0))
+	}
+	return json.Marshal(h)
+}
+
+func (h *RedirectHops) Scan(value interface{}) error {
+	source, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(source, &h)
+}
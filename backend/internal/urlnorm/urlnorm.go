@@ -0,0 +1,140 @@
+// Package urlnorm reduces the different spellings of a URL to a single canonical form,
+// used to detect duplicate websites and to match links found while crawling.
+package urlnorm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+var ErrInvalidURL = errors.New("invalid url")
+
+// Normalizer canonicalizes URLs: it lowercases the scheme and host, drops default ports,
+// fragments and tracking query parameters, sorts the query and removes trailing slashes.
+type Normalizer struct {
+	tracking map[string]bool
+	prefixes []string
+}
+
+// New returns a Normalizer stripping the given query parameters. A parameter ending in "*"
+// strips every parameter starting with the rest of it, such as "utm_*". Names are case-insensitive.
+func New(trackingParams []string) *Normalizer {
+	n := &Normalizer{tracking: make(map[string]bool)}
+	for _, param := range trackingParams {
+		param = strings.ToLower(strings.TrimSpace(param))
+		if param == "" {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			n.prefixes = append(n.prefixes, prefix)
+		} else {
+			n.tracking[param] = true
+		}
+	}
+	return n
+}
+
+// Normalize checks that raw is an absolute http or https URL with a host and without
+// credentials, and returns its canonical form.
+func (n *Normalizer) Normalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("%w: unsupported scheme %q", ErrInvalidURL, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("%w: missing host", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return "", fmt.Errorf("%w: credentials are not allowed", ErrInvalidURL)
+	}
+	return n.String(u), nil
+}
+
+// String returns the canonical form of u, which is left unchanged.
+func (n *Normalizer) String(u *url.URL) string {
+	return n.URL(u).String()
+}
+
+// URL returns a canonical copy of u.
+func (n *Normalizer) URL(u *url.URL) *url.URL {
+	norm := *u
+	norm.Scheme = strings.ToLower(u.Scheme)
+	norm.Fragment = ""
+	norm.RawFragment = ""
+	if norm.Opaque != "" {
+		return &norm
+	}
+
+	host := Hostname(u)
+	port := u.Port()
+	if (norm.Scheme == "http" && port == "80") || (norm.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	switch {
+	case port != "":
+		norm.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		norm.Host = "[" + host + "]"
+	default:
+		norm.Host = host
+	}
+
+	if norm.Path == "" {
+		norm.Path = "/"
+		norm.RawPath = ""
+	} else if len(norm.Path) > 1 {
+		norm.Path = strings.TrimRight(norm.Path, "/")
+		norm.RawPath = strings.TrimRight(norm.RawPath, "/")
+		if norm.Path == "" {
+			norm.Path = "/"
+			norm.RawPath = ""
+		}
+	}
+
+	norm.RawQuery = n.query(u.RawQuery)
+	norm.ForceQuery = false
+	return &norm
+}
+
+// query drops tracking parameters and sorts the rest by name, keeping the order of repeated
+// parameters. A query that cannot be parsed is kept as is.
+func (n *Normalizer) query(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	for name := range values {
+		if n.isTracking(name) {
+			values.Del(name)
+		}
+	}
+	// Encode sorts by name
+	return values.Encode()
+}
+
+func (n *Normalizer) isTracking(name string) bool {
+	name = strings.ToLower(name)
+	if n.tracking[name] {
+		return true
+	}
+	for _, prefix := range n.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hostname returns the host of u without port, lowercased and without a trailing dot.
+func Hostname(u *url.URL) string {
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
@@ -1,9 +1,11 @@
 package websocket
 
 import (
-	"bytes"
 	"log"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -13,20 +15,40 @@ const (
 	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 512
+	maxMessageSize = 4096
 )
 
-var (
-	newline = []byte{'\n'}
-	space   = []byte{' '}
-)
+var newline = []byte{'\n'}
+
+// Subprotocol is the WebSocket subprotocol of the event protocol. Clients that offer
+// credentials as subprotocols must offer it too, since browsers expect one to be selected.
+const Subprotocol = "crawler.v1"
+
+// RequestedSubprotocols returns the subprotocols offered by a handshake request.
+func RequestedSubprotocols(r *http.Request) []string {
+	return websocket.Subprotocols(r)
+}
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
+// checkOrigin accepts handshakes from the allowed origins, "*" allowing any origin.
+// Requests without an Origin header do not come from browsers and are accepted.
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		origin = strings.ToLower(u.Scheme + "://" + u.Host)
+		for _, allowed := range allowedOrigins {
+			if allowed == "*" || strings.ToLower(strings.TrimSuffix(allowed, "/")) == origin {
+				return true
+			}
+		}
+		return false
+	}
 }
 
 // Client is a middleman between the websocket connection and the hub.
@@ -36,14 +58,55 @@ type Client struct {
 	// The websocket connection.
 	conn *websocket.Conn
 
-	// Buffered channel of outbound messages.
-	send chan []byte
+	// The outbound messages and subscriptions. Clients receive nothing until they subscribe.
+	*subscriber
+}
+
+// handleMessage applies a subscribe or unsubscribe message and answers with the resulting
+// subscriptions, or replays missed events for a resume message. Messages that do not follow
+// the protocol are answered with an error event.
+func (c *Client) handleMessage(data []byte) {
+	message, err := parseClientMessage(data)
+	if err != nil {
+		c.hub.reply(c.subscriber, NewEvent(EventError, 0, map[string]string{"message": err.Error()}))
+		return
+	}
+	if message.Type == MessageResume {
+		c.hub.resume <- resumeFrom{subscriber: c.subscriber, seq: message.resumeFrom}
+		return
+	}
+
+	c.mu.Lock()
+	switch {
+	case message.Type == MessageSubscribe && message.all():
+		c.all = true
+	case message.Type == MessageSubscribe:
+		for _, id := range message.websiteIDs() {
+			c.websites[id] = true
+		}
+	case message.all():
+		// Unsubscribing from all websites drops every subscription
+		c.all = false
+		clear(c.websites)
+	default:
+		for _, id := range message.websiteIDs() {
+			delete(c.websites, id)
+		}
+	}
+	subscriptions := subscriptionRequest{All: c.all, WebsiteIDs: make([]uint, 0, len(c.websites))}
+	for id := range c.websites {
+		subscriptions.WebsiteIDs = append(subscriptions.WebsiteIDs, id)
+	}
+	c.mu.Unlock()
+
+	slices.Sort(subscriptions.WebsiteIDs)
+	c.hub.reply(c.subscriber, NewEvent(EventSubscriptions, 0, subscriptions))
 }
 
-// readPump pumps messages from the websocket connection to the hub.
+// readPump reads subscription and resume messages from the websocket connection.
 func (c *Client) readPump() {
 	defer func() {
-		c.hub.unregister <- c
+		c.hub.unregister <- c.subscriber
 		c.conn.Close()
 	}()
 	c.conn.SetReadLimit(maxMessageSize)
@@ -57,8 +120,7 @@ func (c *Client) readPump() {
 			}
 			break
 		}
-		message = bytes.TrimSpace(bytes.ReplaceAll(message, newline, space))
-		c.hub.broadcast <- message
+		c.handleMessage(message)
 	}
 }
 
@@ -84,7 +146,7 @@ func (c *Client) writePump() {
 			}
 			w.Write(message)
 
-			// Add queued chat messages to the current websocket message.
+			// Add queued events to the current websocket message, one per line.
 			n := len(c.send)
 			for range n {
 				w.Write(newline)
@@ -103,15 +165,21 @@ func (c *Client) writePump() {
 	}
 }
 
-// ServeWs handles websocket requests from the peer.
-func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
+// ServeWs handles websocket requests from the peer, rejecting handshakes from origins that are not allowed.
+func ServeWs(hub *Hub, allowedOrigins []string, w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		Subprotocols:    []string{Subprotocol},
+		CheckOrigin:     checkOrigin(allowedOrigins),
+	}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	client := &Client{hub: hub, conn: conn, send: make(chan []byte, 256)}
-	client.hub.register <- client
+	client := &Client{hub: hub, conn: conn, subscriber: newSubscriber()}
+	client.hub.register <- registration{subscriber: client.subscriber}
 
 	go client.writePump()
 	go client.readPump()
@@ -0,0 +1,160 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ProtocolVersion is the version of the event envelope, sent with every event.
+const ProtocolVersion = 1
+
+// EventType identifies what an event's payload holds.
+type EventType string
+
+const (
+	// Events sent by the server
+	EventScanStatus    EventType = "scan.status"
+	EventScanProgress  EventType = "scan.progress"
+	EventAlert         EventType = "alert"
+	EventSubscriptions EventType = "subscriptions"
+	EventResumed       EventType = "resumed"
+	EventStale         EventType = "stale"
+	EventError         EventType = "error"
+
+	// Messages sent by clients
+	MessageSubscribe   EventType = "subscribe"
+	MessageUnsubscribe EventType = "unsubscribe"
+	MessageResume      EventType = "resume"
+)
+
+// Event is the envelope of every message exchanged over the websocket.
+// Events without a website are sent to every client. Published events carry a
+// sequence number that increases with every event, replies to a single client carry none.
+type Event struct {
+	Type      EventType `json:"type"`
+	Version   int       `json:"version"`
+	Seq       uint64    `json:"seq,omitempty"`
+	WebsiteID uint      `json:"websiteId,omitempty"`
+	Payload   any       `json:"payload,omitempty"`
+}
+
+// NewEvent returns an event of the current protocol version.
+func NewEvent(eventType EventType, websiteID uint, payload any) Event {
+	return Event{Type: eventType, Version: ProtocolVersion, WebsiteID: websiteID, Payload: payload}
+}
+
+// subscriptionRequest is the payload of subscribe and unsubscribe messages.
+type subscriptionRequest struct {
+	WebsiteIDs []uint `json:"websiteIds"`
+	All        bool   `json:"all"`
+}
+
+// resumeRequest is the payload of resume messages, naming the last event the client received.
+type resumeRequest struct {
+	ResumeFrom *uint64 `json:"resumeFrom"`
+}
+
+// clientMessage is a message sent by a client. Subscriptions name either a single website in
+// websiteId, several in payload.websiteIds, or all of them with payload.all.
+type clientMessage struct {
+	Type      EventType       `json:"type"`
+	Version   int             `json:"version"`
+	WebsiteID uint            `json:"websiteId"`
+	Payload   json.RawMessage `json:"payload"`
+
+	subscription subscriptionRequest
+	resumeFrom   uint64
+}
+
+// parseClientMessage decodes a message sent by a client and checks it against the protocol.
+func parseClientMessage(data []byte) (*clientMessage, error) {
+	var message clientMessage
+	if err := decodeStrict(data, &message); err != nil {
+		return nil, fmt.Errorf("invalid message: %v", err)
+	}
+
+	if message.Version != ProtocolVersion {
+		return nil, fmt.Errorf("unsupported version %d, expected %d", message.Version, ProtocolVersion)
+	}
+
+	switch message.Type {
+	case MessageSubscribe, MessageUnsubscribe:
+		return &message, message.parseSubscription()
+	case MessageResume:
+		return &message, message.parseResume()
+	default:
+		return nil, fmt.Errorf("unknown message type %q", message.Type)
+	}
+}
+
+func (m *clientMessage) parseSubscription() error {
+	if len(m.Payload) > 0 {
+		if err := decodeStrict(m.Payload, &m.subscription); err != nil {
+			return fmt.Errorf("invalid payload: %v", err)
+		}
+	}
+
+	targets := 0
+	if m.WebsiteID != 0 {
+		targets++
+	}
+	if len(m.subscription.WebsiteIDs) > 0 {
+		targets++
+	}
+	if m.subscription.All {
+		targets++
+	}
+	for _, id := range m.subscription.WebsiteIDs {
+		if id == 0 {
+			return errors.New("websiteIds must be positive")
+		}
+	}
+	if targets != 1 {
+		return errors.New("exactly one of websiteId, payload.websiteIds and payload.all must be set")
+	}
+	return nil
+}
+
+func (m *clientMessage) parseResume() error {
+	if m.WebsiteID != 0 {
+		return errors.New("resume does not take a websiteId")
+	}
+	var request resumeRequest
+	if len(m.Payload) > 0 {
+		if err := decodeStrict(m.Payload, &request); err != nil {
+			return fmt.Errorf("invalid payload: %v", err)
+		}
+	}
+	if request.ResumeFrom == nil {
+		return errors.New("payload.resumeFrom must be set")
+	}
+	m.resumeFrom = *request.ResumeFrom
+	return nil
+}
+
+// websiteIDs returns the websites named by a subscription message.
+func (m *clientMessage) websiteIDs() []uint {
+	if m.WebsiteID != 0 {
+		return []uint{m.WebsiteID}
+	}
+	return m.subscription.WebsiteIDs
+}
+
+func (m *clientMessage) all() bool {
+	return m.subscription.All
+}
+
+// decodeStrict decodes a single JSON value, rejecting unknown fields and trailing data.
+func decodeStrict(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("unexpected data after the message")
+	}
+	return nil
+}
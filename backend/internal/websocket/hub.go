@@ -1,49 +1,192 @@
 package websocket
 
-import "fmt"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
-// Hub maintains the set of active clients and broadcasts messages to the clients.
+// published is an encoded event kept for replaying to reconnecting clients.
+type published struct {
+	seq       uint64
+	websiteID uint
+	data      []byte
+}
+
+// direct is an encoded event for a single subscriber.
+type direct struct {
+	subscriber *subscriber
+	data       []byte
+}
+
+// seqEpochShift leaves room for 2^21 events per second of uptime before a later start's numbers
+// are reached, while keeping sequence numbers below 2^53 until 2106, so that JavaScript clients
+// read them exactly.
+const seqEpochShift = 21
+
+// resumeFrom asks the hub to replay to a subscriber the events published after seq.
+type resumeFrom struct {
+	subscriber *subscriber
+	seq        uint64
+}
+
+// registration adds a subscriber to the hub. When from is set, the events published after it are
+// replayed in the same step, so that none of them is also delivered live.
+type registration struct {
+	subscriber *subscriber
+	from       *uint64
+}
+
+// Hub maintains the set of active subscribers, websocket clients and others, and sends events
+// to the subscribers of their website. It keeps the most recent events so that subscribers can
+// catch up after reconnecting.
 type Hub struct {
-	clients    map[*Client]bool
-	broadcast  chan []byte
-	register   chan *Client
-	unregister chan *Client
+	clients    map[*subscriber]bool
+	broadcast  chan Event
+	direct     chan direct
+	resume     chan resumeFrom
+	register   chan registration
+	unregister chan *subscriber
+
+	// seq is the sequence number of the last published event, history a ring buffer of
+	// the last events indexed by sequence number, and buffered the number of events in it
+	seq      uint64
+	history  []published
+	buffered int
 }
 
-func NewHub() *Hub {
+func NewHub(replayBuffer int) *Hub {
 	return &Hub{
-		broadcast:  make(chan []byte),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		clients:    make(map[*Client]bool),
+		broadcast:  make(chan Event),
+		direct:     make(chan direct),
+		resume:     make(chan resumeFrom),
+		register:   make(chan registration),
+		unregister: make(chan *subscriber),
+		clients:    make(map[*subscriber]bool),
+		// Numbering from the start time keeps sequence numbers increasing across restarts,
+		// so clients resuming with a number from before a restart are told they are stale.
+		seq:     uint64(time.Now().Unix()) << seqEpochShift,
+		history: make([]published, max(replayBuffer, 1)),
 	}
 }
 
 func (h *Hub) Run() {
 	for {
 		select {
-		case client := <-h.register:
-			h.clients[client] = true
+		case request := <-h.register:
+			h.clients[request.subscriber] = true
 			fmt.Println("Client registered")
+			if request.from != nil {
+				h.replay(request.subscriber, *request.from)
+			}
 		case client := <-h.unregister:
 			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
+				h.drop(client)
 				fmt.Println("Client unregistered")
 			}
-		case message := <-h.broadcast:
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-				}
+		case event := <-h.broadcast:
+			h.publish(event)
+		case message := <-h.direct:
+			if h.clients[message.subscriber] {
+				h.send(message.subscriber, message.data)
+			}
+		case request := <-h.resume:
+			if h.clients[request.subscriber] {
+				h.replay(request.subscriber, request.seq)
+			}
+		}
+	}
+}
+
+func (h *Hub) publish(event Event) {
+	event.Seq = h.seq + 1
+	data, err := json.Marshal(event)
+	if err != nil {
+		fmt.Println("Error marshalling event:", err)
+		return
+	}
+	h.seq = event.Seq
+	h.history[h.seq%uint64(len(h.history))] = published{seq: h.seq, websiteID: event.WebsiteID, data: data}
+	h.buffered = min(h.buffered+1, len(h.history))
+
+	for client := range h.clients {
+		if client.subscribed(event.WebsiteID) {
+			if client.firstLive == 0 {
+				client.firstLive = h.seq
 			}
+			h.send(client, data)
 		}
 	}
 }
 
-func (h *Hub) Broadcast(message []byte) {
-	h.broadcast <- message
+// replay sends a client the events it is subscribed to that were published after seq, followed
+// by a resumed event. Events from the first one it received live on are not sent again. When
+// some of those events are no longer kept, or seq is from before a restart, the client is sent a
+// stale event instead and should refetch what it shows.
+func (h *Hub) replay(client *subscriber, seq uint64) {
+	oldest := h.seq - uint64(h.buffered) + 1
+	if seq > h.seq || seq+1 < oldest {
+		h.sendEvent(client, NewEvent(EventStale, 0, map[string]uint64{"latestSeq": h.seq}))
+		return
+	}
+
+	var missed bytes.Buffer
+	replayed := 0
+	for s := seq + 1; s <= h.seq; s++ {
+		event := h.history[s%uint64(len(h.history))]
+		if (client.firstLive != 0 && s >= client.firstLive) || !client.subscribed(event.websiteID) {
+			continue
+		}
+		missed.Write(event.data)
+		missed.Write(newline)
+		replayed++
+	}
+
+	resumed, err := json.Marshal(NewEvent(EventResumed, 0, map[string]any{"replayed": replayed, "latestSeq": h.seq}))
+	if err != nil {
+		fmt.Println("Error marshalling event:", err)
+		return
+	}
+	// The missed events go out as a single message, so that they cannot overflow the client's queue
+	missed.Write(resumed)
+	h.send(client, missed.Bytes())
+}
+
+func (h *Hub) sendEvent(client *subscriber, event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		fmt.Println("Error marshalling event:", err)
+		return
+	}
+	h.send(client, data)
+}
+
+// send queues data for a subscriber, dropping subscribers that do not keep up.
+func (h *Hub) send(client *subscriber, data []byte) {
+	select {
+	case client.send <- data:
+	default:
+		h.drop(client)
+	}
+}
+
+func (h *Hub) drop(client *subscriber) {
+	delete(h.clients, client)
+	close(client.send)
+}
+
+// Publish numbers an event and sends it to the clients subscribed to its website.
+func (h *Hub) Publish(event Event) {
+	h.broadcast <- event
+}
+
+// reply sends an event to a single subscriber, if it is still connected.
+func (h *Hub) reply(client *subscriber, event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		fmt.Println("Error marshalling event:", err)
+		return
+	}
+	h.direct <- direct{subscriber: client, data: data}
 }
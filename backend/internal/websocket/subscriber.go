@@ -0,0 +1,64 @@
+package websocket
+
+import "sync"
+
+// subscriber is a consumer of hub events: a websocket client or a Subscription.
+type subscriber struct {
+	// Buffered channel of outbound messages.
+	send chan []byte
+
+	// The websites whose events the subscriber receives.
+	mu       sync.Mutex
+	all      bool
+	websites map[uint]bool
+
+	// firstLive is the sequence number of the first event published to the subscriber. It has
+	// every later event it is subscribed to, which replays skip. Only the hub's goroutine uses it.
+	firstLive uint64
+}
+
+func newSubscriber() *subscriber {
+	return &subscriber{send: make(chan []byte, 256), websites: make(map[uint]bool)}
+}
+
+// subscribed reports whether the subscriber receives the events of a website.
+// Events that are not about a website go to every subscriber.
+func (s *subscriber) subscribed(websiteID uint) bool {
+	if websiteID == 0 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.all || s.websites[websiteID]
+}
+
+// Subscription streams hub events outside a websocket connection, such as to server-sent events.
+type Subscription struct {
+	hub        *Hub
+	subscriber *subscriber
+}
+
+// Subscribe starts a subscription to the events of the given websites, or of all websites when
+// none are given. When from is set, the events published after it are replayed first,
+// exactly as for a websocket client resuming.
+func (h *Hub) Subscribe(websiteIDs []uint, from *uint64) *Subscription {
+	s := newSubscriber()
+	s.all = len(websiteIDs) == 0
+	for _, id := range websiteIDs {
+		s.websites[id] = true
+	}
+
+	h.register <- registration{subscriber: s, from: from}
+	return &Subscription{hub: h, subscriber: s}
+}
+
+// Events returns the encoded events of the subscription. Several events may come in one
+// message, one per line. The channel is closed when the subscriber falls too far behind.
+func (s *Subscription) Events() <-chan []byte {
+	return s.subscriber.send
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	s.hub.unregister <- s.subscriber
+}
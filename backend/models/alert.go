@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AlertRule raises an alert when a crawl result meets a condition: Metric compared with Value using
+// Operator, or Metric differing from the previous run when Operator is "changed". Rules without a
+// website apply to every website.
+type AlertRule struct {
+	gorm.Model
+
+	Name      string `json:"name"`
+	WebsiteID *uint  `json:"websiteId,omitempty" gorm:"index;default:null"`
+	Metric    string `json:"metric" gorm:"type:varchar(32);not null"`
+	Operator  string `json:"operator" gorm:"type:varchar(16);not null"`
+	Value     string `json:"value,omitempty"`
+	Enabled   bool   `json:"enabled" gorm:"not null"`
+}
+
+type AlertState string
+
+const (
+	AlertFiring   AlertState = "firing"
+	AlertResolved AlertState = "resolved"
+)
+
+// Alert is a rule whose condition held for a website. It keeps firing while the condition holds
+// after each scan and is resolved by the first scan where it no longer does.
+type Alert struct {
+	gorm.Model
+
+	AlertRuleID uint       `json:"alertRuleId" gorm:"index;not null"`
+	AlertRule   *AlertRule `json:"alertRule,omitempty"`
+	WebsiteID   uint       `json:"websiteId" gorm:"index;not null"`
+	State       AlertState `json:"state" gorm:"type:varchar(20);index;not null"`
+	// Message describes the condition and the value that triggered it.
+	Message    string     `json:"message" gorm:"type:text"`
+	CrawlRunID uint       `json:"crawlRunId"`
+	FiredAt    time.Time  `json:"firedAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty" gorm:"default:null"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+// CrawlCheckpoint holds the progress of a paused crawl run, so that resuming
+// the run continues where it stopped instead of fetching every page again.
+type CrawlCheckpoint struct {
+	gorm.Model
+
+	WebsiteID  uint `json:"websiteId" gorm:"index;not null"`
+	CrawlRunID uint `json:"crawlRunId" gorm:"index;not null"`
+	// State is the crawler's frontier, visited set and partial totals, encoded as JSON.
+	State string `json:"-" gorm:"type:longtext;not null"`
+}
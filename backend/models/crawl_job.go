@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobDone      JobStatus = "done"
+	JobCancelled JobStatus = "cancelled"
+	JobPaused    JobStatus = "paused"
+)
+
+// CrawlJob is a queued request to scan a website. Running jobs hold a lease that
+// their worker keeps renewing; a job whose lease expired is handed to another worker.
+type CrawlJob struct {
+	gorm.Model
+
+	WebsiteID      uint       `json:"websiteId" gorm:"index;not null"`
+	Status         JobStatus  `json:"status" gorm:"type:varchar(20);index;not null"`
+	Attempts       int        `json:"attempts"`
+	LeaseOwner     string     `json:"leaseOwner,omitempty"`
+	LeaseExpiresAt *time.Time `json:"leaseExpiresAt,omitempty" gorm:"index;default:null"`
+	// CrawlRunID is the run a paused job resumes, 0 for a job that starts a new run.
+	CrawlRunID uint `json:"crawlRunId,omitempty"`
+}
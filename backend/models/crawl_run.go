@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CrawlRun is the snapshot of a single scan of a website.
+type CrawlRun struct {
+	gorm.Model
+
+	WebsiteID     uint        `json:"websiteId" gorm:"index;not null"`
+	Status        StatusType  `json:"status" gorm:"type:varchar(20);not null"`
+	StartedAt     time.Time   `json:"startedAt"`
+	FinishedAt    *time.Time  `json:"finishedAt,omitempty" gorm:"default:null"`
+	FailureCode   FailureCode `json:"failureCode,omitempty" gorm:"type:varchar(32)"`
+	FailureReason string      `json:"failureReason,omitempty"`
+
+	CrawlMetrics `gorm:"embedded"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"web-crawler/backend/internal/types"
+
+	"gorm.io/gorm"
+)
+
+// Link is an anchor discovered on a crawled page, along with the result of checking its target.
+type Link struct {
+	gorm.Model
+
+	WebsiteID  uint   `json:"websiteId" gorm:"index;not null"`
+	CrawlRunID uint   `json:"crawlRunId" gorm:"index;not null"`
+	SourceURL  string `json:"sourceUrl" gorm:"type:varchar(2048);not null"`
+	TargetURL  string `json:"targetUrl" gorm:"type:varchar(2048);not null"`
+	AnchorText string `json:"anchorText" gorm:"type:text"`
+	IsInternal bool   `json:"isInternal"`
+
+	StatusCode   int                `json:"statusCode"`
+	Broken       bool               `json:"broken" gorm:"index"`
+	Error        string             `json:"error,omitempty"`
+	RedirectHops types.RedirectHops `json:"redirectHops" gorm:"type:json"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"web-crawler/backend/internal/types"
+
+	"gorm.io/gorm"
+)
+
+// SkipReasonRobots marks pages that were not fetched because robots.txt disallows them.
+const SkipReasonRobots = "robots"
+
+// Page holds the analysis results of a single page visited while crawling a website.
+type Page struct {
+	gorm.Model
+
+	WebsiteID  uint   `json:"websiteId" gorm:"index;not null"`
+	CrawlRunID uint   `json:"crawlRunId" gorm:"index;not null"`
+	URL        string `json:"url" gorm:"type:varchar(2048);not null"`
+	Depth      int    `json:"depth"`
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error,omitempty"`
+	SkipReason string `json:"skipReason,omitempty"`
+
+	HTMLVersion   string        `json:"htmlVersion"`
+	Title         string        `json:"title"`
+	HeadingsCount types.JSONMap `json:"headingsCount" gorm:"type:json"`
+	InternalLinks int           `json:"internalLinks"`
+	ExternalLinks int           `json:"externalLinks"`
+	HasLoginForm  bool          `json:"hasLoginForm"`
+}
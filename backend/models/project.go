@@ -0,0 +1,11 @@
+package models
+
+import "gorm.io/gorm"
+
+// Project groups the websites of a client. A website belongs to at most one project.
+type Project struct {
+	gorm.Model
+
+	Name        string `json:"name" gorm:"size:191;uniqueIndex;not null"`
+	Description string `json:"description" gorm:"type:text"`
+}
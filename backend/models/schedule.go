@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// Schedule starts scans of a website automatically, either on a cron expression
+// (evaluated in UTC) or at a fixed interval. Exactly one of Cron and Interval is set.
+type Schedule struct {
+	gorm.Model
+
+	WebsiteID uint   `json:"websiteId" gorm:"uniqueIndex;not null"`
+	Cron      string `json:"cron,omitempty"`
+	// Interval is a Go duration such as "30m" or "24h".
+	Interval string `json:"interval,omitempty"`
+	Enabled  bool   `json:"enabled" gorm:"not null"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	// SitemapOrphan marks a sitemap URL that no crawled page links to.
+	SitemapOrphan = "orphan"
+	// SitemapUnlisted marks an internally linked URL that is missing from the sitemap.
+	SitemapUnlisted = "unlisted"
+)
+
+// SitemapIssue is a mismatch between a website's sitemap and its internal links.
+type SitemapIssue struct {
+	gorm.Model
+
+	WebsiteID  uint   `json:"websiteId" gorm:"index;not null"`
+	CrawlRunID uint   `json:"crawlRunId" gorm:"index;not null"`
+	URL        string `json:"url" gorm:"type:varchar(2048);not null"`
+	Kind       string `json:"kind" gorm:"type:varchar(20);not null"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "gorm.io/gorm"
+
+// Tag labels websites; a website can have any number of tags.
+type Tag struct {
+	gorm.Model
+
+	Name string `json:"name" gorm:"size:64;uniqueIndex;not null"`
+}
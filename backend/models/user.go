@@ -0,0 +1,50 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Role grants access to the API: viewers read results, editors also manage websites and scans,
+// admins also manage users, webhooks and the trash.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// Roles lists the roles from least to most privileged.
+var Roles = []Role{RoleViewer, RoleEditor, RoleAdmin}
+
+// Includes reports whether r grants at least the access of other.
+func (r Role) Includes(other Role) bool {
+	rank := slices.Index(Roles, r)
+	return rank >= 0 && rank >= slices.Index(Roles, other)
+}
+
+// User is an account signing in with its email and password.
+type User struct {
+	gorm.Model
+
+	Email        string `json:"email" gorm:"size:191;uniqueIndex;not null"`
+	Name         string `json:"name"`
+	PasswordHash string `json:"-" gorm:"not null"`
+	Role         Role   `json:"role" gorm:"type:varchar(20);not null"`
+	// Disabled users cannot sign in and lose their sessions.
+	Disabled    bool       `json:"disabled" gorm:"not null;default:false"`
+	LastLoginAt *time.Time `json:"lastLoginAt,omitempty" gorm:"default:null"`
+}
+
+// Session is a signed-in user. Only a hash of its token is stored.
+type Session struct {
+	gorm.Model
+
+	UserID     uint      `json:"userId" gorm:"index;not null"`
+	TokenHash  string    `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt  time.Time `json:"expiresAt" gorm:"index;not null"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+}
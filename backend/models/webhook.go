@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+	"web-crawler/backend/internal/types"
+
+	"gorm.io/gorm"
+)
+
+type WebhookEvent string
+
+const (
+	WebhookCrawlCompleted       WebhookEvent = "crawl.completed"
+	WebhookCrawlFailed          WebhookEvent = "crawl.failed"
+	WebhookBrokenLinksIncreased WebhookEvent = "broken_links.increased"
+)
+
+// WebhookEvents lists the events webhooks can subscribe to.
+var WebhookEvents = []WebhookEvent{WebhookCrawlCompleted, WebhookCrawlFailed, WebhookBrokenLinksIncreased}
+
+// Webhook is a subscription of an external URL to crawl events. Payloads are signed with Secret.
+type Webhook struct {
+	gorm.Model
+
+	URL     string           `json:"url" gorm:"type:varchar(2048);not null"`
+	Secret  string           `json:"-" gorm:"not null"`
+	Events  types.StringList `json:"events" gorm:"type:json"`
+	Enabled bool             `json:"enabled" gorm:"not null"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is a single event sent to a webhook, with the outcome of its last attempt.
+// Pending deliveries are attempted again once NextAttemptAt has passed.
+type WebhookDelivery struct {
+	gorm.Model
+
+	WebhookID     uint           `json:"webhookId" gorm:"index;not null"`
+	Event         WebhookEvent   `json:"event" gorm:"type:varchar(64);not null"`
+	WebsiteID     uint           `json:"websiteId" gorm:"index"`
+	CrawlRunID    uint           `json:"crawlRunId"`
+	Payload       string         `json:"payload" gorm:"type:longtext"`
+	Status        DeliveryStatus `json:"status" gorm:"type:varchar(20);index;not null"`
+	Attempts      int            `json:"attempts"`
+	NextAttemptAt *time.Time     `json:"nextAttemptAt,omitempty" gorm:"index;default:null"`
+	DeliveredAt   *time.Time     `json:"deliveredAt,omitempty" gorm:"default:null"`
+
+	// The outcome of the last attempt
+	ResponseStatus int    `json:"responseStatus,omitempty"`
+	ResponseBody   string `json:"responseBody,omitempty" gorm:"type:text"`
+	Error          string `json:"error,omitempty" gorm:"type:text"`
+}
@@ -15,22 +15,76 @@ const (
 	Completed StatusType = "completed"
 	Failed    StatusType = "failed"
 	Cancelled StatusType = "cancelled"
+	Paused    StatusType = "paused"
 )
 
+// FailureCode classifies why a scan failed.
+type FailureCode string
+
+const (
+	FailureInvalidURL        FailureCode = "invalid_url"
+	FailureDNS               FailureCode = "dns"
+	FailureTimeout           FailureCode = "timeout"
+	FailureConnectionReset   FailureCode = "connection_reset"
+	FailureConnectionRefused FailureCode = "connection_refused"
+	FailureNetwork           FailureCode = "network"
+	FailureRateLimited       FailureCode = "rate_limited"
+	FailureServerError       FailureCode = "server_error"
+	FailureClientError       FailureCode = "client_error"
+	FailureUnexpectedStatus  FailureCode = "unexpected_status"
+	FailureInternal          FailureCode = "internal"
+)
+
+// CrawlMetrics holds the results of a crawl, shared by a website and each of its crawl runs.
+type CrawlMetrics struct {
+	HTMLVersion   string        `json:"htmlVersion"`
+	Title         string        `json:"title"`
+	HeadingsCount types.JSONMap `json:"headingsCount" gorm:"type:json"`
+	InternalLinks int           `json:"internalLinks"`
+	ExternalLinks int           `json:"externalLinks"`
+	BrokenLinks   int           `json:"brokenLinks"`
+	HasLoginForm  bool          `json:"hasLoginForm"`
+	PagesCrawled  int           `json:"pagesCrawled"`
+	RobotsSkipped int           `json:"robotsSkipped"`
+	SitemapFound  bool          `json:"sitemapFound"`
+	SitemapURLs   int           `json:"sitemapUrls"`
+	OrphanPages   int           `json:"orphanPages"`
+	UnlistedPages int           `json:"unlistedPages"`
+}
+
 // Website represents a website to be crawled.
 type Website struct {
 	gorm.Model
 
-	URL    string     `json:"url" gorm:"unique;not null"`
+	URL string `json:"url" gorm:"unique;not null"`
+	// NormalizedURL is the canonical form of URL, unique across websites so that different
+	// spellings of the same address are not added twice. It is null for websites added before
+	// normalization whose canonical form collides with another website.
+	NormalizedURL *string `json:"normalizedUrl,omitempty" gorm:"size:191;uniqueIndex"`
+
 	Status StatusType `json:"status" gorm:"type:varchar(20);default:'queued';not null"`
 
-	HTMLVersion     string        `json:"htmlVersion"`
-	Title           string        `json:"title"`
-	HeadingsCount   types.JSONMap `json:"headingsCount" gorm:"type:json"`
-	InternalLinks   int           `json:"internalLinks"`
-	ExternalLinks   int           `json:"externalLinks"`
-	BrokenLinks     int           `json:"brokenLinks"`
-	HasLoginForm    bool          `json:"hasLoginForm"`
-	CrawlStartedAt  *time.Time    `json:"crawlStartedAt,omitempty" gorm:"default:null"`
-	CrawlFinishedAt *time.Time    `json:"crawlFinishedAt,omitempty" gorm:"default:null"`
+	// ProjectID is the project the website belongs to, if any. Deleting the project ungroups its websites.
+	ProjectID *uint    `json:"projectId,omitempty" gorm:"index;default:null"`
+	Project   *Project `json:"project,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	Tags      []Tag    `json:"tags,omitempty" gorm:"many2many:website_tags;constraint:OnDelete:CASCADE"`
+
+	// MaxDepth is the number of internal link hops followed from URL; 0 only crawls URL itself.
+	MaxDepth int `json:"maxDepth" gorm:"default:0;not null"`
+	// MaxPages caps the number of pages fetched in a single crawl.
+	MaxPages int `json:"maxPages" gorm:"default:1;not null"`
+	// IgnoreRobots disables robots.txt compliance, for sites we own.
+	IgnoreRobots bool `json:"ignoreRobots" gorm:"default:false;not null"`
+
+	CrawlMetrics    `gorm:"embedded"`
+	CrawlStartedAt  *time.Time `json:"crawlStartedAt,omitempty" gorm:"default:null"`
+	CrawlFinishedAt *time.Time `json:"crawlFinishedAt,omitempty" gorm:"default:null"`
+
+	// FailureCode and FailureReason explain why the last scan failed; both are empty otherwise.
+	FailureCode   FailureCode `json:"failureCode,omitempty" gorm:"type:varchar(32)"`
+	FailureReason string      `json:"failureReason,omitempty" gorm:"type:text"`
+
+	// NextRunAt is when the website's schedule starts its next scan, LastRunAt when it last started one.
+	NextRunAt *time.Time `json:"nextRunAt,omitempty" gorm:"index;default:null"`
+	LastRunAt *time.Time `json:"lastRunAt,omitempty" gorm:"default:null"`
 }
@@ -0,0 +1,107 @@
+"use client";
+
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Form, FormControl, FormField, FormItem, FormLabel, FormMessage } from "@/components/ui/form";
+import { Input } from "@/components/ui/input";
+import { setSessionToken } from "@/lib/session";
+import { showErrorToast } from "@/lib/toasts";
+import { login } from "@/services/urlsService";
+import { zodResolver } from "@hookform/resolvers/zod";
+import { useMutation } from "@tanstack/react-query";
+import { AxiosError } from "axios";
+import { Loader, LogIn } from "lucide-react";
+import { useRouter } from "next/navigation";
+import { useForm } from "react-hook-form";
+import z from "zod";
+
+const formSchema = z.object({
+  email: z.email(),
+  password: z.string().min(1, "Password is required"),
+})
+
+export default function LoginPage() {
+  const router = useRouter();
+
+  const form = useForm<z.infer<typeof formSchema>>({
+    resolver: zodResolver(formSchema),
+    defaultValues: {
+      email: '',
+      password: ''
+    }
+  })
+
+  const mutation = useMutation({
+    mutationFn: (data: z.infer<typeof formSchema>) => {
+      return login(data.email, data.password);
+    }
+  })
+
+  function onSubmit(data: z.infer<typeof formSchema>) {
+    mutation.mutate(data, {
+      onSuccess: (response) => {
+        setSessionToken(response.data.token);
+        router.replace('/');
+      },
+      onError: (error) => {
+        console.error("Error logging in:", error);
+        if (error instanceof AxiosError && error.response?.status === 401) {
+          showErrorToast("Invalid email or password.");
+        } else {
+          showErrorToast("An unexpected error occurred. Please try again.");
+        }
+      }
+    });
+  }
+
+  return (
+    <div className="container mx-auto p-6 max-w-md">
+      <Card>
+        <CardHeader>
+          <CardTitle>Log In</CardTitle>
+          <CardDescription>Sign in with your account to use the Web Crawler Dashboard</CardDescription>
+        </CardHeader>
+        <CardContent>
+          <Form {...form}>
+            <form onSubmit={form.handleSubmit(onSubmit)} className="space-y-4">
+              <FormField
+                control={form.control}
+                name="email"
+                render={({ field }) => (
+                  <FormItem>
+                    <FormLabel>Email</FormLabel>
+                    <FormControl>
+                      <Input type="email" autoComplete="username" {...field} />
+                    </FormControl>
+                    <FormMessage />
+                  </FormItem>
+                )}
+              />
+              <FormField
+                control={form.control}
+                name="password"
+                render={({ field }) => (
+                  <FormItem>
+                    <FormLabel>Password</FormLabel>
+                    <FormControl>
+                      <Input type="password" autoComplete="current-password" {...field} />
+                    </FormControl>
+                    <FormMessage />
+                  </FormItem>
+                )}
+              />
+              <Button type="submit" className="w-full" disabled={mutation.isPending}>
+                {mutation.isPending ? (
+                  <Loader className="w-4 h-4 mr-2 animate-spin" />
+                ) : (
+                  <LogIn className="w-4 h-4 mr-2"/>
+                )}
+                Log In
+              </Button>
+            </form>
+          </Form>
+        </CardContent>
+      </Card>
+    </div>
+  )
+}
@@ -3,17 +3,40 @@
 import AddURL from "@/components/AddUrl";
 import UrlsTable from "@/components/UrlsTable";
 import WebSocketManager from "@/components/WebSocketManager";
+import { Button } from "@/components/ui/button";
+import { clearSessionToken } from "@/lib/session";
+import { logout } from "@/services/urlsService";
+import { LogOut } from "lucide-react";
+import { useRouter } from "next/navigation";
 import { QueryClient, QueryClientProvider } from "@tanstack/react-query";
 
 
 
 export default function Home() {
+  const router = useRouter();
+
+  const handleLogout = async () => {
+    try {
+      await logout();
+    } catch (error) {
+      console.error("Error logging out:", error);
+    }
+    clearSessionToken();
+    router.replace('/login');
+  }
+
   return (
     <div>
       <WebSocketManager />
       <div className="container mx-auto p-6 space-y-6">
         <div className="flex flex-col space-y-4">
-          <h1 className="text-3xl font-bold">Web Crawler Dashboard</h1>
+          <div className="flex items-center justify-between">
+            <h1 className="text-3xl font-bold">Web Crawler Dashboard</h1>
+            <Button variant="outline" onClick={handleLogout}>
+              <LogOut className="w-4 h-4 mr-2"/>
+              Log Out
+            </Button>
+          </div>
           <p className="text-muted-foregrond">
             Analyze websites and track their key metrics including HTML structure, links, and accessibility.
           </p>
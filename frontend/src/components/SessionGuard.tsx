@@ -0,0 +1,29 @@
+"use client";
+
+import { getSessionToken } from "@/lib/session";
+import { usePathname, useRouter } from "next/navigation";
+import { useEffect, useState } from "react";
+
+const PUBLIC_PATHS = ['/login'];
+
+// SessionGuard only shows the dashboard to signed in users, sending everyone else to the login page.
+export default function SessionGuard({
+    children,
+    }: Readonly<{
+    children: React.ReactNode;
+}>) {
+    const pathname = usePathname();
+    const router = useRouter();
+    const [allowed, setAllowed] = useState(false);
+
+    useEffect(() => {
+        if (PUBLIC_PATHS.includes(pathname) || getSessionToken()) {
+            setAllowed(true);
+            return;
+        }
+        setAllowed(false);
+        router.replace('/login');
+    }, [pathname, router]);
+
+    return allowed ? <>{children}</> : null;
+}
@@ -308,7 +308,20 @@ export default function UrlsTable() {
                           onClick={e => e.stopPropagation()}
                         />
                       </TableCell>
-                      <TableCell>{getStatusBadge(url.status)}</TableCell>
+                      <TableCell>
+                        {getStatusBadge(url.status)}
+                        {url.status === CrawlStatus.Crawling && url.progress && (
+                          <div className="mt-1 w-24">
+                            <div className="h-1.5 rounded-full bg-muted">
+                              <div className="h-1.5 rounded-full bg-blue-500"
+                                style={{ width: `${Math.min(100, url.progress.pagesFetched / Math.max(1, url.progress.maxPages) * 100)}%` }} />
+                            </div>
+                            <div className="text-xs text-muted-foreground">
+                              {url.progress.pagesFetched}/{url.progress.maxPages} pages
+                            </div>
+                          </div>
+                        )}
+                      </TableCell>
                       <TableCell className="font-medium">{url.title || "No title"}</TableCell>
                       <TableCell className="max-w-xs truncate">{url.url}</TableCell>
                       <TableCell>{url.htmlVersion || "N/A"}</TableCell>
@@ -1,42 +1,60 @@
 "use client";
 
-import { useWebSocket } from '@/hooks/useWebSocket';
+import { useWebSocket, WebSocketEvent } from '@/hooks/useWebSocket';
 import { useQueryClient } from '@tanstack/react-query';
-import { useEffect } from 'react';
+import { useCallback } from 'react';
 import { PaginatedUrls, URL } from '@/types/urls.types';
 import { fetchUrlById } from '@/services/urlsService';
 
 export default function WebSocketManager() {
-  const { lastMessage } = useWebSocket();
   const queryClient = useQueryClient();
 
-  useEffect(() => {
-    if (lastMessage) {
-      const updateUrlData = async () => {
-        try {
-          const response = await fetchUrlById(lastMessage.id);
-          const updatedUrl = response.data;
-
-          queryClient.setQueriesData<PaginatedUrls>({ queryKey: ['urls'] }, (oldData) => {
-            if (!oldData) return oldData;
-
-            const newData = oldData.data.map((url: URL) => {
-              if (url.ID === updatedUrl.ID) {
-                return updatedUrl;
-              }
-              return url;
-            });
-
-            return { ...oldData, data: newData };
-          });
-        } catch (error) {
-          console.error("Failed to fetch URL by ID:", error);
+  const updateUrl = useCallback((id: number, update: (url: URL) => URL) => {
+    queryClient.setQueriesData<PaginatedUrls>({ queryKey: ['urls'] }, (oldData) => {
+      if (!oldData) return oldData;
+
+      const newData = oldData.data.map((url: URL) => {
+        if (url.ID === id) {
+          return update(url);
         }
-      };
+        return url;
+      });
+
+      return { ...oldData, data: newData };
+    });
+  }, [queryClient]);
+
+  const onMessage = useCallback((message: WebSocketEvent) => {
+    // Events were missed while disconnected and can no longer be replayed
+    if (message.type === 'stale') {
+      queryClient.invalidateQueries({ queryKey: ['urls'] });
+      return;
+    }
+
+    const websiteId = message.websiteId;
+    if (!websiteId) return;
 
-      updateUrlData();
+    // Progress events carry everything needed, no need to refetch the URL
+    if (message.type === 'scan.progress') {
+      updateUrl(websiteId, url => ({ ...url, progress: message.payload }));
+      return;
     }
-  }, [lastMessage, queryClient]);
+    if (message.type !== 'scan.status') return;
+
+    const updateUrlData = async () => {
+      try {
+        const response = await fetchUrlById(websiteId);
+        const updatedUrl = response.data;
+        updateUrl(updatedUrl.ID, () => updatedUrl);
+      } catch (error) {
+        console.error("Failed to fetch URL by ID:", error);
+      }
+    };
+
+    updateUrlData();
+  }, [queryClient, updateUrl]);
+
+  useWebSocket(onMessage);
 
   return null;
 }
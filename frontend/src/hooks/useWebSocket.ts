@@ -1,37 +1,102 @@
 import { env } from '@/lib/env';
+import { fetchWsTicket } from '@/services/urlsService';
 import { useEffect, useState, useRef } from 'react';
 
 const WS_URL = env.NEXT_PUBLIC_WS_URL;
 
-export function useWebSocket() {
-  const [lastMessage, setLastMessage] = useState<any>(null);
+export const WS_PROTOCOL_VERSION = 1;
+const WS_SUBPROTOCOL = 'crawler.v1';
+
+export interface WebSocketEvent<T = any> {
+  type:       string;
+  version:    number;
+  seq?:       number;
+  websiteId?: number;
+  payload?:   T;
+}
+
+// The server may batch several events in one frame, separated by newlines,
+// so each event is handed to onMessage rather than kept as the last one.
+// The connection subscribes to the events of every website. Browsers cannot set headers
+// on the handshake, so each connection authenticates with a fresh ticket instead.
+// After a reconnect the events missed in between are replayed, or a 'stale' event
+// tells onMessage that the data shown must be refetched.
+export function useWebSocket(onMessage: (event: WebSocketEvent) => void) {
   const ws = useRef<WebSocket | null>(null);
+  const onMessageRef = useRef(onMessage);
+  const lastSeq = useRef<number | null>(null);
   const [isConnected, setIsConnected] = useState(false);
 
   useEffect(() => {
-    const connect = () => {
+    onMessageRef.current = onMessage;
+  }, [onMessage]);
+
+  useEffect(() => {
+    let closed = false;
+
+    const connect = async () => {
+      if (closed) return;
       if (ws.current && ws.current.readyState === WebSocket.OPEN) return;
 
-      ws.current = new WebSocket(WS_URL);
+      let ticket: string;
+      try {
+        const response = await fetchWsTicket();
+        ticket = response.data.ticket;
+      } catch (error) {
+        console.error('Failed to get WebSocket ticket:', error);
+        setTimeout(connect, 5000);
+        return;
+      }
+      if (closed) return;
+
+      ws.current = new WebSocket(WS_URL, [WS_SUBPROTOCOL, `ticket.${ticket}`]);
 
       ws.current.onopen = () => {
         console.log('WebSocket connected');
         setIsConnected(true);
+        ws.current?.send(JSON.stringify({
+          type: 'subscribe',
+          version: WS_PROTOCOL_VERSION,
+          payload: { all: true },
+        }));
+        if (lastSeq.current !== null) {
+          ws.current?.send(JSON.stringify({
+            type: 'resume',
+            version: WS_PROTOCOL_VERSION,
+            payload: { resumeFrom: lastSeq.current },
+          }));
+        }
       };
 
       ws.current.onmessage = (event) => {
-        try {
-          const message = JSON.parse(event.data);
-          setLastMessage(message);
-        } catch (error) {
-          console.error('Failed to parse WebSocket message:', error);
+        for (const data of String(event.data).split('\n')) {
+          if (!data) continue;
+          try {
+            const message: WebSocketEvent = JSON.parse(data);
+            if (message.version !== WS_PROTOCOL_VERSION) {
+              console.warn('Ignoring WebSocket event of unsupported version:', message.version);
+              continue;
+            }
+            if (message.type === 'error') {
+              console.error('WebSocket error event:', message.payload?.message);
+              continue;
+            }
+            if (message.seq !== undefined) {
+              lastSeq.current = message.seq;
+            } else if (message.type === 'stale' || message.type === 'resumed') {
+              lastSeq.current = message.payload.latestSeq;
+            }
+            onMessageRef.current(message);
+          } catch (error) {
+            console.error('Failed to parse WebSocket message:', error);
+          }
         }
       };
 
       ws.current.onclose = () => {
         console.log('WebSocket disconnected');
         setIsConnected(false);
-        setTimeout(connect, 5000);
+        if (!closed) setTimeout(connect, 5000);
       };
 
       ws.current.onerror = (error) => {
@@ -43,11 +108,12 @@ export function useWebSocket() {
     connect();
 
     return () => {
+      closed = true;
       if (ws.current) {
         ws.current.close();
       }
     };
   }, []);
 
-  return { lastMessage, isConnected };
+  return { isConnected };
 }
@@ -5,11 +5,9 @@ export const env = createEnv({
   client: {
     NEXT_PUBLIC_API_URL: z.url(),
     NEXT_PUBLIC_WS_URL: z.url(),
-    NEXT_PUBLIC_API_KEY: z.string().min(1),
   },
   runtimeEnv: {
     NEXT_PUBLIC_API_URL: process.env.NEXT_PUBLIC_API_URL,
     NEXT_PUBLIC_WS_URL: process.env.NEXT_PUBLIC_WS_URL,
-    NEXT_PUBLIC_API_KEY: process.env.NEXT_PUBLIC_API_KEY,
   },
 });
@@ -0,0 +1,15 @@
+const SESSION_KEY = 'session';
+
+// The session token from logging in is kept in local storage and sent as a bearer token.
+export function getSessionToken(): string | null {
+    if (typeof window === 'undefined') return null;
+    return window.localStorage.getItem(SESSION_KEY);
+}
+
+export function setSessionToken(token: string) {
+    window.localStorage.setItem(SESSION_KEY, token);
+}
+
+export function clearSessionToken() {
+    window.localStorage.removeItem(SESSION_KEY);
+}
@@ -7,7 +7,8 @@ export const getStatusBadge = (status: CrawlStatus) => {
     [CrawlStatus.Completed]: "bg-green-100 text-green-800",
     [CrawlStatus.Failed]: "bg-red-100 text-red-800",
     [CrawlStatus.Crawling]: "bg-blue-100 text-blue-800",
-    [CrawlStatus.Cancelled]: "bg-gray-100 text-gray-800"
+    [CrawlStatus.Cancelled]: "bg-gray-100 text-gray-800",
+    [CrawlStatus.Paused]: "bg-orange-100 text-orange-800"
   };
 
   return (
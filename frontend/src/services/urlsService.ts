@@ -1,18 +1,45 @@
 import { FiltersState } from "@/hooks/useUrlFilters";
 import { env } from "@/lib/env";
+import { clearSessionToken, getSessionToken } from "@/lib/session";
+import { LoginResponse, User } from "@/types/auth.types";
 import { PaginatedUrls, URL } from "@/types/urls.types";
 import axios from "axios";
 
 const apiUrl = env.NEXT_PUBLIC_API_URL;
-const apiKey = env.NEXT_PUBLIC_API_KEY;
 
 const api = axios.create({
     baseURL: apiUrl,
-    headers: {
-        'X-API-Key': apiKey,
+});
+
+api.interceptors.request.use((config) => {
+    const token = getSessionToken();
+    if (token) {
+        config.headers.Authorization = `Bearer ${token}`;
     }
+    return config;
 });
 
+// An expired or revoked session sends the user back to the login page
+api.interceptors.response.use(undefined, (error) => {
+    if (axios.isAxiosError(error) && error.response?.status === 401 && !error.config?.url?.startsWith('/auth/login')) {
+        clearSessionToken();
+        window.location.assign('/login');
+    }
+    return Promise.reject(error);
+});
+
+export function login(email: string, password: string) {
+    return api.post<LoginResponse>(`/auth/login`, { email, password });
+}
+
+export function logout() {
+    return api.post(`/auth/logout`);
+}
+
+export function fetchCurrentUser() {
+    return api.get<User>(`/auth/me`);
+}
+
 export function postNewUrl(newUrl: string) {
     return api.post<URL>(`/urls`, { url: newUrl });
 }
@@ -55,3 +82,7 @@ export function cancelScanUrl(urlId: number) {
 export function bulkScanUrls(urlIds: number[]) {
     return api.post(`/urls/bulk-scan`, { ids: urlIds });
 }
+
+export function fetchWsTicket() {
+    return api.post<{ ticket: string; expiresAt: string }>(`/ws/ticket`);
+}
@@ -0,0 +1,22 @@
+export interface User {
+    ID:           number;
+    CreatedAt:    Date;
+    UpdatedAt:    Date;
+    email:        string;
+    name:         string;
+    role:         Role;
+    disabled:     boolean;
+    lastLoginAt?: Date;
+}
+
+export enum Role {
+    Viewer = "viewer",
+    Editor = "editor",
+    Admin = "admin"
+}
+
+export interface LoginResponse {
+    token:     string;
+    expiresAt: string;
+    user:      User;
+}
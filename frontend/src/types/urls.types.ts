@@ -10,7 +10,14 @@ export interface URL {
     UpdatedAt:        Date;
     DeletedAt:        Date | null;
     url:              string;
+    normalizedUrl?:   string;
     status:           CrawlStatus;
+    projectId?:       number;
+    project?:         Project;
+    tags?:            Tag[];
+    maxDepth:         number;
+    maxPages:         number;
+    ignoreRobots:     boolean;
     htmlVersion:      string;
     title:            string;
     headingsCount:    HeadingsCount;
@@ -18,8 +25,43 @@ export interface URL {
     externalLinks:    number;
     brokenLinks:      number;
     hasLoginForm:     boolean;
+    pagesCrawled:     number;
+    robotsSkipped:    number;
+    sitemapFound:     boolean;
+    sitemapUrls:      number;
+    orphanPages:      number;
+    unlistedPages:    number;
     crawlStartedAt:   Date | null;
     crawlCompletedAt: Date | null;
+    failureCode?:     string;
+    failureReason?:   string;
+    nextRunAt?:       Date;
+    lastRunAt?:       Date;
+    progress?:        CrawlProgress;
+}
+
+export interface Project {
+    ID:          number;
+    CreatedAt:   Date;
+    UpdatedAt:   Date;
+    name:        string;
+    description: string;
+}
+
+export interface Tag {
+    ID:        number;
+    CreatedAt: Date;
+    UpdatedAt: Date;
+    name:      string;
+}
+
+export interface CrawlProgress {
+    pagesFetched: number;
+    pagesQueued:  number;
+    maxPages:     number;
+    linksChecked: number;
+    brokenLinks:  number;
+    elapsedMs:    number;
 }
 
 export interface HeadingsCount {
@@ -36,7 +78,8 @@ export enum CrawlStatus {
     Crawling = "crawling",
     Completed = "completed",
     Failed = "failed",
-    Cancelled = "cancelled"
+    Cancelled = "cancelled",
+    Paused = "paused"
 }
 
 export interface Pagination {
@@ -2,7 +2,11 @@ import { test, expect } from '@playwright/test';
 
 test.describe.serial('URL Management', () => {
   test.beforeEach(async ({ page }) => {
-    await page.goto('/');
+    await page.goto('/login');
+    await page.getByLabel('Email').fill(process.env.E2E_EMAIL ?? 'admin@example.com');
+    await page.getByLabel('Password').fill(process.env.E2E_PASSWORD ?? 'change-me-please');
+    await page.getByRole('button', { name: 'Log In' }).click();
+    await page.waitForURL('/');
   });
 
   test('should add a new URL', async ({ page }) => {